@@ -0,0 +1,98 @@
+package csvdata
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"encoding/csv"
+	"errors"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// A CompressedOption configures NewCompressedReader.
+type CompressedOption func(*compressedConfig)
+
+type compressedConfig struct {
+	autoDetect bool
+}
+
+// WithAutoDetect has NewCompressedReader tell the format from the magic
+// number at the start of the data rather than from its format argument.
+// Data that is not compressed in a known format is read as it is.
+func WithAutoDetect() CompressedOption {
+	return func(this *compressedConfig) {
+		this.autoDetect = true
+	}
+}
+
+// NewCompressedReader returns a csv.Reader over r decompressed as format,
+// one of "gzip", "zstd" or "bzip2"; an empty format reads r as it is.
+func NewCompressedReader(r io.Reader, format string, opts ...CompressedOption) (Reader, error) {
+	var cfg compressedConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.autoDetect {
+		br := bufio.NewReader(r)
+		format = detectFormat(br)
+		r = br
+	}
+	var dr io.Reader
+	switch format {
+	case "":
+		dr = r
+	case "gzip":
+		zr, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		dr = zr
+	case "zstd":
+		zr, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		dr = zr.IOReadCloser()
+	case "bzip2":
+		dr = bzip2.NewReader(r)
+	default:
+		return nil, errors.New("unknown compression format " + format)
+	}
+	return csv.NewReader(dr), nil
+}
+
+// detectFormat returns the compression format of the data in br from its
+// magic number, or "".
+func detectFormat(br *bufio.Reader) string {
+	magic, _ := br.Peek(4)
+	switch {
+	case bytes.HasPrefix(magic, []byte{0x1f, 0x8b}):
+		return "gzip"
+	case bytes.HasPrefix(magic, []byte{0x28, 0xb5, 0x2f, 0xfd}):
+		return "zstd"
+	case bytes.HasPrefix(magic, []byte("BZh")):
+		return "bzip2"
+	}
+	return ""
+}
+
+// NewCompressedWriter returns a writer that compresses what is written to
+// it as format, "gzip" or "zstd", into w, e.g. to be given to
+// NewWriteIter. Close must be called, after WriteIter.Flush, to finish the
+// compressed stream; it does not close w.
+func NewCompressedWriter(w io.Writer, format string) (io.WriteCloser, error) {
+	switch format {
+	case "gzip":
+		return gzip.NewWriter(w), nil
+	case "zstd":
+		zw, err := zstd.NewWriter(w)
+		if err != nil {
+			return nil, err
+		}
+		return zw, nil
+	}
+	return nil, errors.New("cannot write compression format " + format)
+}
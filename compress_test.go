@@ -0,0 +1,92 @@
+package csvdata
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+const compressData = "A,B\n1,x\n2,y\n"
+
+var compressRows = [][]string{{"A", "B"}, {"1", "x"}, {"2", "y"}}
+
+// compressed returns compressData compressed as format.
+func compressed(t *testing.T, format string) []byte {
+	t.Helper()
+	var b bytes.Buffer
+	var w io.WriteCloser
+	switch format {
+	case "gzip":
+		w = gzip.NewWriter(&b)
+	case "zstd":
+		zw, err := zstd.NewWriter(&b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w = zw
+	default:
+		return []byte(compressData)
+	}
+	io.WriteString(w, compressData)
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return b.Bytes()
+}
+
+func TestCompressedReader(t *testing.T) {
+	for _, format := range []string{"", "gzip", "zstd"} {
+		data := compressed(t, format)
+		rdr, err := NewCompressedReader(bytes.NewReader(data), format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := readRows(t, rdr); !reflect.DeepEqual(got, compressRows) {
+			t.Fatalf("%q: got %q", format, got)
+		}
+		rdr, err = NewCompressedReader(bytes.NewReader(data), "unused", WithAutoDetect())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := readRows(t, rdr); !reflect.DeepEqual(got, compressRows) {
+			t.Fatalf("%q detected: got %q", format, got)
+		}
+	}
+	if _, err := NewCompressedReader(bytes.NewReader(nil), "lzma"); err == nil {
+		t.Fatal("unknown format passed")
+	}
+}
+
+func TestCompressedWriter(t *testing.T) {
+	type row struct {
+		A int
+		B string
+	}
+	for _, format := range []string{"gzip", "zstd"} {
+		var b bytes.Buffer
+		cw, err := NewCompressedWriter(&b, format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = WriteAll(cw, []row{{1, "x"}, {2, "y"}}); err != nil {
+			t.Fatal(err)
+		}
+		if err = cw.Close(); err != nil {
+			t.Fatal(err)
+		}
+		rdr, err := NewCompressedReader(&b, format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := readRows(t, rdr); !reflect.DeepEqual(got, compressRows) {
+			t.Fatalf("%s: got %q", format, got)
+		}
+	}
+	if _, err := NewCompressedWriter(io.Discard, "bzip2"); err == nil {
+		t.Fatal("bzip2 output passed")
+	}
+}
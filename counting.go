@@ -0,0 +1,62 @@
+package csvdata
+
+import (
+	"errors"
+	"io"
+	"sync/atomic"
+)
+
+// CountingReader is an io.Reader that counts the bytes read through it,
+// to be put between a file and csv.NewReader.
+type CountingReader struct {
+	r io.Reader
+	n atomic.Int64
+}
+
+// NewCountingReader returns a CountingReader reading from r.
+func NewCountingReader(r io.Reader) (*CountingReader, error) {
+	if r == nil {
+		return nil, errors.New("cannot count a nil io.Reader")
+	}
+	return &CountingReader{r: r}, nil
+}
+
+func (this *CountingReader) Read(p []byte) (int, error) {
+	n, err := this.r.Read(p)
+	this.n.Add(int64(n))
+	return n, err
+}
+
+// BytesRead returns the number of bytes read so far. It may be called
+// from another goroutine.
+func (this *CountingReader) BytesRead() int64 {
+	return this.n.Load()
+}
+
+// ProgressReader passes on the rows of a Reader whose bytes come through
+// a CountingReader, and tells how far through a source of known size it
+// is. As csv.Reader reads ahead, the figure runs a little early.
+type ProgressReader struct {
+	Reader
+	counter *CountingReader
+	size    int64
+}
+
+// NewProgressReader returns a ProgressReader for rdr, which reads from
+// counter a source of size bytes.
+func NewProgressReader(rdr Reader, counter *CountingReader, size int64) *ProgressReader {
+	return &ProgressReader{Reader: rdr, counter: counter, size: size}
+}
+
+// Percent returns the share of the source read so far, from 0 to 100,
+// or 0 if the size is unknown.
+func (this *ProgressReader) Percent() float64 {
+	if this.size <= 0 {
+		return 0
+	}
+	p := float64(this.counter.BytesRead()) * 100 / float64(this.size)
+	if p > 100 {
+		p = 100
+	}
+	return p
+}
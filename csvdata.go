@@ -14,6 +14,84 @@ package csvdata
 // name are converted to spaces when comparing. Otherwise, you must provide a tag
 // 'field' with the name of the column.
 //
+// Fields of embedded structs are matched as if they were declared in the
+// outer struct, following the rules of encoding/json: an outer field hides
+// a promoted one with the same column name.
+//
+// An 'alias' tag lists other names to try, in order, when no column has
+// the field's own name, e.g. `alias:"given_name,GivenName"`.
+//
+// Integer fields may be given in another base with a 'base' tag of 2, 8,
+// 10 or 16, e.g. `base:"16"`; a 0x prefix is allowed in base 16.
+//
+// A `thousands:"true"` tag removes thousand separators from a numeric
+// cell, so that "1,234,567" can be read; WithThousandSeparator does so for
+// every numeric field.
+//
+// WithDecimalSeparator reads floats written with another decimal point,
+// such as "1.234,56" together with WithThousandSeparator('.'). A 'decimal'
+// tag overrides it for one field, e.g. `decimal:"."`.
+//
+// time.Duration fields are parsed with time.ParseDuration, e.g. "1h30m";
+// an empty cell is 0.
+//
+// net.IP fields take an IPv4 or IPv6 address; an empty cell is nil.
+//
+// A float field tagged `percent:"true"` reads "75.5%" as 0.755; a cell
+// without the % sign is taken as it is.
+//
+// A file without a header row is read with the WithNoHeader option; every
+// field then needs a 'col' tag with its zero-based column, e.g. `col:"2"`.
+//
+// An 'index' tag, e.g. `index:"3"`, fixes the zero-based column of a
+// field. It is used when no header matches the field's name or aliases,
+// and in place of 'col' in a file without headers.
+//
+// A struct field tagged `rechunk:";"` is read from a cell of key=value
+// pairs, such as "k1=v1;k2=v2", whose keys name the columns of the inner
+// struct; a 'kvsep' tag changes the '='.
+//
+// A field tagged `ignore:"true"` is never read or written.
+//
+// A `trim:"true"` tag strips white space from the cell before it is
+// converted; `trim:"false"` keeps it even when WithTrimSpace is in effect.
+//
+// A `required:"true"` tag makes an empty cell an error.
+//
+// Numeric fields may be given bounds, e.g. `min:"0" max:"150"`; a value
+// outside them, defaults included, makes Get return a RangeError.
+// A string field tagged `regex:"^[A-Z]{2}[0-9]{4}$"` must match the
+// pattern unless the cell is empty, or Get returns a RegexError.
+// Tags `minlen:"1" maxlen:"50"` bound the length in characters of a
+// string cell, after trimming; a violation is a LengthError.
+//
+// A 'default' tag gives the value used when a cell is empty, or when the
+// column is missing altogether, e.g. `field:"Score" default:"100"`.
+//
+// A slice field such as []string or []int holds a cell of several values
+// split by the separator in the 'sep' tag, e.g. `sep:"|"`. An empty cell gives an empty slice, or
+// nil with the omitempty option, as in `field:"Tags,omitempty"`.
+// When several columns share the field's header, the slice collects the
+// non-empty cells of all of them; without a 'sep' tag each cell is one value.
+//
+// Types that implement encoding.TextUnmarshaler are converted with
+// UnmarshalText, in preference to Value. A field whose type is the Value
+// interface itself is filled by the function given with WithFieldFactory.
+//
+// Pointer fields such as *int are left nil when the cell is empty and
+// otherwise point to a newly allocated value.
+//
+// time.Time fields are parsed with the layout given in the 'format' tag, e.g.
+// `format:"2006-01-02"`, or RFC3339 if there is none. An empty cell is the zero time.
+// `format:"epoch"` and `format:"epoch_ms"` read Unix time in seconds or
+// milliseconds instead.
+// On float fields the 'format' tag, e.g. `format:"f:2"`, gives the fmt and
+// prec used by WriteIter.
+//
+// Quoted cells that span several lines, as allowed by RFC 4180, are
+// assigned to string fields with their newlines intact; csv.Reader turns
+// \r\n inside the quotes into \n. Line counts rows, not lines of text.
+//
 //     r := csv.NewReader(os.Stdin)
 //     p := new (Person)
 //     rs,_ := NewReaderIter(r,p)
@@ -25,14 +103,21 @@ package csvdata
 //    }
 
 import (
+	"context"
+	"encoding"
+	"encoding/csv"
 	"errors"
+	"fmt"
 	"io"
+	"net"
 	//	"os"
-	"fmt"
 	"reflect"
+	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
 )
 
 // The data source is any object that has a Read method which can
@@ -49,16 +134,148 @@ type Value interface {
 	Set(string) bool
 }
 
+// A user struct whose pointer implements Computed has Compute called by
+// Get once all its fields are set, to fill in derived fields.
+type Computed interface {
+	Compute()
+}
+
+// ValueWithError is like Value for types whose Set reports why a cell was
+// rejected; the error is wrapped in a ParseError by Get. As with Value,
+// the method must be defined on a pointer receiver.
+type ValueWithError interface {
+	Set(string) error
+}
+
 // ReadIter encapsulates an iterator over a Reader source that fills a
 // pointer to a user struct with data.
 type ReadIter struct {
 	Reader       Reader
 	Headers      []string
 	Error        error
+	Errors       []error // errors of skipped rows, see WithSkipErrors
 	Line, Column int
+	RawRow       []string      // the row last read by Get, as converted
+	value        reflect.Value // the user struct
+	ctx          context.Context
+	peeked       bool // peekRow and peekErr hold the next row
+	peekRow      []string
+	peekErr      error
+	rows         int  // data rows read so far
+	returned     int  // rows returned by Get, for WithLimit
+	skipped      bool // the rows of WithOffset have been skipped
 	fields       []reflect.Value
 	kinds        []int
 	tags         []int
+	more         [][]int // further columns of a slice field
+	transforms   [][]func(string) string
+	beforeRow    func(row []string) []string
+	afterRow     func()
+	onHeader     func(headers []string) []string
+	onError      func(err error, line int, row []string) error
+	specs        []fieldSpec
+
+	// options
+	delimiter     rune
+	comment       rune
+	trimSpace     bool
+	caseSensitive bool
+	lazyErrors    bool
+	skipErrors    bool
+	noHeader      bool
+	strictFields  bool
+	strictHeaders bool
+	thousands     rune
+	decimal       rune
+	selected      []string
+	normalize     func(string) string
+	keepBOM       bool
+	jsonFallback  bool
+	factories     map[string]func() Value
+	closer        io.Closer
+	limit         int
+	unicodeNorm   func(string) string // see WithUnicodeNorm
+	columnMap     map[string]string
+	mapping       *FieldMapping
+	offset        int
+	progress      func(line int)
+	progressEvery int
+}
+
+// fieldSpec holds the per-field settings taken from the struct tags.
+type fieldSpec struct {
+	field        string // name of the struct field
+	format       string // layout for time.Time fields, or "f:2" for floats
+	hasFormat    bool   // format was given by a tag
+	def          string // value used for an empty cell
+	hasDef       bool
+	required     bool   // an empty cell is an error
+	trim         bool   // strip white space from the cell
+	hasTrim      bool   // trim overrides WithTrimSpace
+	ptr          bool   // the field is a pointer to the converted type
+	sep          string // separator of the values of a slice field
+	elemKind     int    // kind of the values of a slice field
+	base         int    // base of an integer field, 0 for 10
+	thousandsTag bool   // from `thousands:"true"`
+	thousands    rune   // separator removed from numbers, or 0
+	decimalTag   rune   // from the 'decimal' tag, or 0
+	decimal      rune   // decimal separator of floats, or 0 for '.'
+	percent      bool   // "75.5%" is read as 0.755
+	omitEmpty    bool   // an empty cell is a nil slice
+	floatFmt     byte   // fmt and prec for strconv.FormatFloat
+	floatPrec    int
+	min, max     float64 // bounds from the 'min' and 'max' tags
+	hasMin       bool
+	hasMax       bool
+	regex        *regexp.Regexp // pattern a non-empty cell must match
+	factory      func() Value   // makes the value of an interface field
+	rechunk      rune           // separator of the pairs of a sub_k cell
+	kvSep        rune           // separator of a key and its value
+	minLen       int            // from the 'minlen' tag, or 0
+	maxLen       int            // from the 'maxlen' tag, or -1
+}
+
+// intBase returns the base for parsing an integer field.
+func (spec *fieldSpec) intBase() int {
+	if spec.base == 0 {
+		return 10
+	}
+	return spec.base
+}
+
+// number removes any thousand separators from a numeric cell.
+func (spec *fieldSpec) number(vals string) string {
+	if spec.thousands != 0 {
+		vals = strings.Replace(vals, string(spec.thousands), "", -1)
+	}
+	return vals
+}
+
+// float prepares a float cell: it removes thousand separators and turns
+// the decimal separator into a point.
+func (spec *fieldSpec) float(vals string) string {
+	vals = spec.number(vals)
+	if spec.decimal != 0 && spec.decimal != '.' {
+		vals = strings.Replace(vals, string(spec.decimal), ".", -1)
+	}
+	return vals
+}
+
+// digits prepares an integer cell: it removes thousand separators and
+// strips the 0x prefix in base 16.
+func (spec *fieldSpec) digits(vals string) string {
+	vals = spec.number(vals)
+	if spec.base != 16 {
+		return vals
+	}
+	sign := ""
+	if strings.HasPrefix(vals, "-") || strings.HasPrefix(vals, "+") {
+		sign, vals = vals[:1], vals[1:]
+	}
+	if strings.HasPrefix(vals, "0x") || strings.HasPrefix(vals, "0X") {
+		vals = vals[2:]
+	}
+	return sign + vals
 }
 
 const (
@@ -67,9 +284,31 @@ const (
 	int_k
 	float_k
 	uint_k
+	bool_k
+	time_k
 	value_k
+	text_k // encoding.TextUnmarshaler
+	sep_k  // slice from a cell split with the 'sep' tag
+	duration_k
+	ip_k
+	iface_k // interface field filled by a WithFieldFactory function
+	verr_k  // ValueWithError
+	sub_k   // struct read from a cell of key=value pairs, see 'rechunk'
 )
 
+var timeType = reflect.TypeOf(time.Time{})
+var durationType = reflect.TypeOf(time.Duration(0))
+var ipType = reflect.TypeOf(net.IP(nil))
+var valueType = reflect.TypeOf((*Value)(nil)).Elem()
+var valueWithErrorType = reflect.TypeOf((*ValueWithError)(nil)).Elem()
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// onPointer reports whether kind is converted by methods on the pointer
+// to the field rather than by setting the field itself.
+func onPointer(kind int) bool {
+	return kind == value_k || kind == text_k || kind == verr_k
+}
+
 func StrToInt64(s string) int64 {
 	i, err := strconv.ParseInt(s, 10, 0)
 	if err != nil {
@@ -78,278 +317,1295 @@ func StrToInt64(s string) int64 {
 	return i
 }
 
-func mapType(aHeader []string, v reflect.Value) (this *ReadIter, err error) {
-	this = new(ReadIter)
-	this.Line = 1
-	//	t := v.Type()
+// columnName returns the CSV column name for a struct field: the 'field'
+// tag if present, otherwise the field name with underscores as spaces.
+func columnName(f reflect.StructField) string {
+	tag, _ := fieldTag(f)
+	if len(tag) == 0 {
+		tag = f.Name
+		if strings.Contains(tag, "_") {
+			tag = strings.Replace(tag, "_", " ", -1)
+		}
+	}
+	return tag
+}
+
+// fieldTag splits the 'field' tag into the column name and the options
+// after it, as in `field:"Tags,omitempty"`. Only a known option is split
+// off, so that column names may still contain commas.
+func fieldTag(f reflect.StructField) (name string, omitEmpty bool) {
+	name = f.Tag.Get("field")
+	if i := strings.LastIndex(name, ","); i >= 0 && name[i+1:] == "omitempty" {
+		return name[:i], true
+	}
+	return name, false
+}
 
-	st := v.Type() // reflect.TypeOf(v).Elem()
-	//sv := h.Elem()
-	//nf := st.NumField()
-	//this.kinds = make([]int, nf)
-	//this.tags = make([]int, nf)
-	//this.fields = make([]reflect.Value, nf)
+// structField is a field found by typeFields.
+type structField struct {
+	index   []int  // path to the field, through nested structs
+	name    string // column name
+	depth   int
+	tagged  bool
+	col     int      // column from the 'col' or 'index' tag, or -1
+	indexed bool     // col is from the 'index' tag
+	aliases []string // other column names to try, from the 'alias' tag
+	json    string   // name from the 'json' tag, see WithJSONTagFallback
+	kind    int
+	spec    fieldSpec
+	typ     reflect.Type
+}
+
+// fieldCache holds the result of typeFields for each struct type seen, so
+// the reflection walk is done once per type.
+var fieldCache sync.Map // map[reflect.Type][]structField
+
+// typeFields returns the fields of struct type st, from the cache if it
+// has been seen before. The result must not be modified.
+func typeFields(st reflect.Type) ([]structField, error) {
+	if sfs, ok := fieldCache.Load(st); ok {
+		return sfs.([]structField), nil
+	}
+	sfs, err := buildFields(st)
+	if err != nil {
+		return nil, err
+	}
+	cached, _ := fieldCache.LoadOrStore(st, sfs)
+	return cached.([]structField), nil
+}
+
+// buildFields lists the fields of struct type st. Like encoding/json, the
+// fields of embedded structs are promoted, and a field hides a promoted
+// field of the same column name at a greater depth; at the same depth a
+// field with a 'field' tag hides one without, and fields that still tie
+// are all dropped. Named struct fields are flattened in the same way.
+func buildFields(st reflect.Type) (sfs []structField, err error) {
+	var all []structField
+	if err = walkFields(st, nil, &all); err != nil {
+		return
+	}
+	// find the dominant depth and tagging for each column name
+	type rank struct {
+		depth  int
+		tagged bool
+	}
+	best := make(map[string]rank)
+	ties := make(map[string]int) // fields of the best rank
+	for _, sf := range all {
+		key := strings.ToLower(sf.name)
+		r, ok := best[key]
+		switch {
+		case !ok || sf.depth < r.depth || sf.depth == r.depth && sf.tagged && !r.tagged:
+			best[key] = rank{sf.depth, sf.tagged}
+			ties[key] = 1
+		case r == (rank{sf.depth, sf.tagged}):
+			ties[key] = ties[key] + 1
+		}
+	}
+	for _, sf := range all {
+		key := strings.ToLower(sf.name)
+		if best[key] == (rank{sf.depth, sf.tagged}) && ties[key] == 1 {
+			sfs = append(sfs, sf)
+		}
+	}
+	return
+}
 
+func walkFields(st reflect.Type, index []int, sfs *[]structField) (err error) {
 	for i := 0; i < st.NumField(); i++ {
-		f := st.Field(i)  //field
-		val := v.Field(i) // field value
+		f := st.Field(i) //field
+		path := append(append([]int{}, index...), i)
+
+		if !f.Anonymous && !f.IsExported() {
+			continue
+		}
+		// an ignored field is never read or written, like `json:"-"`
+		if ignore, err := boolTag(f, "ignore"); err != nil {
+			return err
+		} else if ignore {
+			continue
+		}
 
 		// ADD BY HZM
-		if val.Kind() == reflect.Struct {
-			var lTime time.Time
-			//fmt.Println(reflect.TypeOf(lTime), val.Type(), val.Type().ConvertibleTo(reflect.TypeOf(lTime)))
-
-			// 非时间的结构体
-			if !val.Type().ConvertibleTo(reflect.TypeOf(lTime)) {
-				//fmt.Println("time.Time")
-				lParentReadIter, _ := mapType(aHeader, val)
-				//fmt.Println("lParentReadIter", len(lParentReadIter.fields), len(lParentReadIter.kinds))
-				this.fields = append(this.fields, lParentReadIter.fields...)
-				this.kinds = append(this.kinds, lParentReadIter.kinds...)
-				this.tags = append(this.tags, lParentReadIter.tags...)
-				//fmt.Println(len(this.fields), len(this.kinds))
+		// 非时间的结构体; embedded structs may also be pointers
+		ft := f.Type
+		if f.Anonymous && ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		_, rechunk := f.Tag.Lookup("rechunk")
+		if ft.Kind() == reflect.Struct && kindOf(ft) == none_k && !rechunk {
+			// an unexported embedded pointer cannot be allocated
+			if ft != f.Type && !f.IsExported() {
 				continue
 			}
-			/*
-				switch val.Interface().(type) {
-				case time.Time:
-					fmt.Println("time.Time")
-				default:
-					lParentReadIter, _ := mapType(aHeader, val)
-					//fmt.Println("lParentReadIter", len(lParentReadIter.fields), len(lParentReadIter.kinds))
-					this.fields = append(this.fields, lParentReadIter.fields...)
-					this.kinds = append(this.kinds, lParentReadIter.kinds...)
-					this.tags = append(this.tags, lParentReadIter.tags...)
-					fmt.Println(len(this.fields), len(this.kinds))
-					continue
+			if err = walkFields(ft, path, sfs); err != nil {
+				return
+			}
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+
+		sf := structField{index: path, name: columnName(f), depth: len(index), col: -1, typ: f.Type}
+		tag, omitEmpty := fieldTag(f)
+		sf.tagged = len(tag) > 0
+		if alias := f.Tag.Get("alias"); len(alias) > 0 {
+			sf.aliases = strings.Split(alias, ",")
+		}
+		if json, _, _ := strings.Cut(f.Tag.Get("json"), ","); json != "-" {
+			sf.json = json
+		}
+		if col, ok := f.Tag.Lookup("col"); ok {
+			if sf.col, err = strconv.Atoi(col); err != nil || sf.col < 0 {
+				return fmt.Errorf("invalid col %q for field %s", col, f.Name)
+			}
+		}
+		if index, ok := f.Tag.Lookup("index"); ok {
+			if sf.col, err = strconv.Atoi(index); err != nil || sf.col < 0 {
+				return fmt.Errorf("invalid index %q for field %s", index, f.Name)
+			}
+			sf.indexed = true
+		}
+		spec := &sf.spec
+		spec.field = f.Name
+		spec.omitEmpty = omitEmpty
+		spec.def, spec.hasDef = f.Tag.Lookup("default")
+		if spec.required, err = boolTag(f, "required"); err != nil {
+			return
+		}
+		_, spec.hasTrim = f.Tag.Lookup("trim")
+		if spec.trim, err = boolTag(f, "trim"); err != nil {
+			return
+		}
+		if spec.required && spec.hasDef {
+			return errors.New("field " + f.Name + " cannot be both required and have a default")
+		}
+		// a pointer field is converted as its element type
+		spec.ptr = f.Type.Kind() == reflect.Ptr
+		ft = f.Type
+		if spec.ptr {
+			ft = ft.Elem()
+		}
+		// an unconvertible field is only an error if it is used
+		sf.kind = kindOf(ft)
+		if rechunk {
+			if ft.Kind() != reflect.Struct {
+				return errors.New("rechunk tag on non-struct field " + f.Name)
+			}
+			if spec.rechunk, err = runeTag(f, "rechunk", ';'); err != nil {
+				return
+			}
+			if spec.kvSep, err = runeTag(f, "kvsep", '='); err != nil {
+				return
+			}
+			sf.kind = sub_k
+		}
+		if spec.thousandsTag, err = boolTag(f, "thousands"); err != nil {
+			return
+		}
+		if spec.percent, err = boolTag(f, "percent"); err != nil {
+			return
+		}
+		if err = rangeTags(f, sf.kind, spec); err != nil {
+			return
+		}
+		if err = lengthTags(f, sf.kind, spec); err != nil {
+			return
+		}
+		if pattern, ok := f.Tag.Lookup("regex"); ok {
+			if sf.kind != string_k {
+				return errors.New("regex tag on non-string field " + f.Name)
+			}
+			if spec.regex, err = regexp.Compile(pattern); err != nil {
+				return fmt.Errorf("invalid regex for field %s: %v", f.Name, err)
+			}
+		}
+		if decimal, ok := f.Tag.Lookup("decimal"); ok {
+			if utf8.RuneCountInString(decimal) != 1 {
+				return fmt.Errorf("invalid decimal %q for field %s", decimal, f.Name)
+			}
+			spec.decimalTag, _ = utf8.DecodeRuneInString(decimal)
+		}
+		if base, ok := f.Tag.Lookup("base"); ok {
+			switch base {
+			case "2", "8", "10", "16":
+				spec.base, _ = strconv.Atoi(base)
+			default:
+				return fmt.Errorf("invalid base %q for field %s", base, f.Name)
+			}
+		}
+		if sf.kind == sep_k {
+			// without a sep tag each column holds one value
+			spec.elemKind = kindOf(ft.Elem())
+			spec.sep = f.Tag.Get("sep")
+		}
+		if sf.kind == float_k || sf.kind == sep_k && spec.elemKind == float_k {
+			spec.floatFmt, spec.floatPrec = 'g', -1
+			if spec.format, spec.hasFormat = f.Tag.Lookup("format"); spec.hasFormat {
+				if spec.floatFmt, spec.floatPrec, err = parseFloatFormat(spec.format); err != nil {
+					return fmt.Errorf("invalid format %q for field %s", spec.format, f.Name)
 				}
-			*/
+			}
+		}
+		if sf.kind == time_k {
+			spec.format, spec.hasFormat = f.Tag.Lookup("format")
+			if len(spec.format) == 0 {
+				spec.format = time.RFC3339
+			}
+		}
+		*sfs = append(*sfs, sf)
+	}
+	return
+}
+
+// rangeTags reads the 'min' and 'max' tags of a numeric field.
+func rangeTags(f reflect.StructField, kind int, spec *fieldSpec) (err error) {
+	for _, key := range []string{"min", "max"} {
+		tag, ok := f.Tag.Lookup(key)
+		if !ok {
+			continue
+		}
+		if kind != int_k && kind != uint_k && kind != float_k {
+			return fmt.Errorf("%s tag on non-numeric field %s", key, f.Name)
 		}
+		bound, err := strconv.ParseFloat(tag, 64)
+		if err != nil {
+			return fmt.Errorf("invalid %s %q for field %s", key, tag, f.Name)
+		}
+		if key == "min" {
+			spec.min, spec.hasMin = bound, true
+		} else {
+			spec.max, spec.hasMax = bound, true
+		}
+	}
+	if spec.hasMin && spec.hasMax && spec.min > spec.max {
+		return fmt.Errorf("min is above max for field %s", f.Name)
+	}
+	return
+}
 
-		// get the corresponding field name and look it up in the headers
-		tag := f.Tag.Get("field")
-		if len(tag) == 0 {
-			tag = f.Name
-			if strings.Contains(tag, "_") {
-				tag = strings.Replace(tag, "_", " ", -1)
+// lengthTags reads the 'minlen' and 'maxlen' tags of a string field.
+func lengthTags(f reflect.StructField, kind int, spec *fieldSpec) error {
+	spec.maxLen = -1
+	for _, key := range []string{"minlen", "maxlen"} {
+		tag, ok := f.Tag.Lookup(key)
+		if !ok {
+			continue
+		}
+		if kind != string_k {
+			return fmt.Errorf("%s tag on non-string field %s", key, f.Name)
+		}
+		n, err := strconv.Atoi(tag)
+		if err != nil || n < 0 {
+			return fmt.Errorf("invalid %s %q for field %s", key, tag, f.Name)
+		}
+		if key == "minlen" {
+			spec.minLen = n
+		} else {
+			spec.maxLen = n
+		}
+	}
+	if spec.maxLen >= 0 && spec.minLen > spec.maxLen {
+		return fmt.Errorf("minlen is above maxlen for field %s", f.Name)
+	}
+	return nil
+}
+
+// parseFloatFormat parses a float format tag such as "f:2" into the fmt
+// and prec arguments of strconv.FormatFloat; "e" alone means prec -1.
+func parseFloatFormat(s string) (format byte, prec int, err error) {
+	verb, digits, found := strings.Cut(s, ":")
+	if len(verb) != 1 || !strings.Contains("beEfgGxX", verb) {
+		return 0, 0, errors.New("invalid float format " + s)
+	}
+	prec = -1
+	if found {
+		if prec, err = strconv.Atoi(digits); err != nil || prec < -1 {
+			return 0, 0, errors.New("invalid float precision " + s)
+		}
+	}
+	return verb[0], prec, nil
+}
+
+// runeTag returns the single character of a tag such as `kvsep:"="`, or
+// def if the tag is missing or empty.
+func runeTag(f reflect.StructField, key string, def rune) (rune, error) {
+	tag := f.Tag.Get(key)
+	if tag == "" {
+		return def, nil
+	}
+	if utf8.RuneCountInString(tag) != 1 {
+		return 0, fmt.Errorf("invalid %s %q for field %s", key, tag, f.Name)
+	}
+	r, _ := utf8.DecodeRuneInString(tag)
+	return r, nil
+}
+
+// boolTag returns the value of a tag such as `required:"true"`; a missing
+// tag is false.
+func boolTag(f reflect.StructField, key string) (bool, error) {
+	tag, ok := f.Tag.Lookup(key)
+	if !ok {
+		return false, nil
+	}
+	on, err := strconv.ParseBool(tag)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s %q for field %s", key, tag, f.Name)
+	}
+	return on, nil
+}
+
+// fieldByIndex is like reflect.Value.FieldByIndex but allocates nil
+// embedded struct pointers on the way.
+func fieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
 			}
+			v = v.Elem()
 		}
+		v = v.Field(x)
+	}
+	return v
+}
 
-		// 遍历对比
-		itag := -1
-		for k, h := range aHeader {
-			fmt.Println(tag, h)
-			if strings.EqualFold(h, tag) {
+// copyStruct returns a copy of the struct v that shares nothing with v
+// through the embedded pointers that fieldByIndex allocates: every pointer
+// on the path of a field is copied too.
+func copyStruct(v reflect.Value) reflect.Value {
+	c := reflect.New(v.Type()).Elem()
+	c.Set(v)
+	// the struct has been mapped, so its tags are valid
+	sfs, _ := typeFields(v.Type())
+	for _, sf := range sfs {
+		ov, cv := v, c
+		for i, x := range sf.index {
+			if i > 0 && ov.Kind() == reflect.Ptr {
+				if ov.IsNil() {
+					break
+				}
+				// a hop shared with an earlier field is already copied
+				if cv.Pointer() == ov.Pointer() {
+					p := reflect.New(ov.Type().Elem())
+					p.Elem().Set(ov.Elem())
+					cv.Set(p)
+				}
+				ov, cv = ov.Elem(), cv.Elem()
+			}
+			ov, cv = ov.Field(x), cv.Field(x)
+		}
+	}
+	return c
+}
 
-				itag = k
+// mapType records, for every field of the struct v that matches a
+// header, the field value, its conversion kind and its column.
+func (this *ReadIter) mapType(v reflect.Value) (err error) {
+	sfs, err := typeFields(v.Type())
+	if err != nil {
+		return
+	}
+	var unmatched []string
+	deselected := make([]bool, len(this.Headers)) // with a field, but not selected
+	for _, sf := range sfs {
+		// 遍历对比
+		if col, ok := this.mapping.column(sf.spec.field); ok {
+			// an external mapping overrides the tags
+			sf.name, sf.aliases, sf.json = col, nil, ""
+		}
+		itag := this.HeaderIndex(sf.name)
+		for _, alias := range sf.aliases {
+			if itag != -1 {
 				break
 			}
+			itag = this.HeaderIndex(alias)
+		}
+		if itag == -1 && this.jsonFallback && sf.json != "" {
+			itag = this.HeaderIndex(sf.json)
+		}
+		// the 'index' tag is the fallback when no header matches
+		if itag == -1 && sf.indexed && sf.col < len(this.Headers) {
+			itag = sf.col
+		}
+		if this.noHeader {
+			if sf.col == -1 {
+				return errors.New("no col tag for field " + sf.name + " in a file without headers")
+			}
+			itag = sf.col
+		}
+		// a column left out by WithSelectColumns is not read at all
+		if itag >= 0 && !this.isSelected(itag) {
+			for k := range this.Headers {
+				if this.sameName(this.Headers[k], this.Headers[itag]) {
+					deselected[k] = true
+				}
+			}
+			continue
 		}
-		fmt.Println(f, val, itag)
-		// 判断是否有该Field
 		if itag == -1 {
+			unmatched = append(unmatched, sf.spec.field)
+		}
+		// 判断是否有该Field; a missing column still gets its default
+		if itag == -1 && !sf.spec.hasDef {
+			if sf.spec.required {
+				return errors.New("no column for required field " + sf.name)
+			}
 			continue
-			/*
-				err = errors.New("cannot find this field " + tag)
-				this = nil
-				return
-			*/
-		}
-		kind := none_k
-		Kind := f.Type.Kind()
-		// this is necessary because Kind can't tell distinguish between a primitive type
-		// and a type derived from it. We're looking for a Value interface defined on
-		// the pointer to this value
-		_, ok := val.Addr().Interface().(Value)
-		if ok {
+		}
+		if sf.kind == none_k {
+			return errors.New("cannot convert this type " + sf.typ.String())
+		}
+		if sf.kind == iface_k && this.factories[sf.spec.field] == nil {
+			return errors.New("no factory for interface field " + sf.spec.field)
+		}
+		val := fieldByIndex(v, sf.index) // field value
+		if onPointer(sf.kind) && !sf.spec.ptr {
 			val = val.Addr()
-			kind = value_k
-		} else {
-			switch Kind {
-			case reflect.Int, reflect.Int16, reflect.Int8, reflect.Int32, reflect.Int64:
-				kind = int_k
-			case reflect.Uint, reflect.Uint16, reflect.Uint8, reflect.Uint32, reflect.Uint64:
-				kind = uint_k
-			case reflect.Float32, reflect.Float64:
-				kind = float_k
-			case reflect.String:
-				kind = string_k
-			default:
-				kind = value_k
-				_, ok := val.Interface().(Value)
-				if !ok {
-					err = errors.New("cannot convert this type ")
-					this = nil
-					return
+		}
+		// a slice collects every column with the same header
+		var more []int
+		if sf.kind == sep_k && !sf.spec.ptr && itag >= 0 && !this.noHeader {
+			for k := itag + 1; k < len(this.Headers); k++ {
+				if this.sameName(this.Headers[k], this.Headers[itag]) && this.isSelected(k) {
+					more = append(more, k)
 				}
 			}
 		}
 		this.fields = append(this.fields, val)
-		this.kinds = append(this.kinds, kind)
+		this.kinds = append(this.kinds, sf.kind)
 		this.tags = append(this.tags, itag)
-		//	this.kinds[i] = kind
-		//	this.tags[i] = itag
-		//	this.fields[i] = val
-		fmt.Println(val, kind, itag)
+		this.more = append(this.more, more)
+		spec := this.localSpec(sf.spec)
+		// reject a bad default now rather than on the first row, with the
+		// separators of this ReadIter
+		if spec.hasDef && sf.kind != iface_k {
+			scratch := reflect.New(sf.typ).Elem()
+			if onPointer(sf.kind) && !spec.ptr {
+				scratch = scratch.Addr()
+			}
+			if err = setValue(scratch, sf.kind, &spec, spec.def); err != nil {
+				return fmt.Errorf("invalid default %q for field %s: %v", spec.def, spec.field, err)
+			}
+		}
+		this.specs = append(this.specs, spec)
+	}
+	if this.strictFields && len(unmatched) > 0 {
+		return errors.New("no column for fields " + strings.Join(unmatched, ", "))
+	}
+	if this.strictHeaders {
+		if extra := this.unmapped(deselected); len(extra) > 0 {
+			return errors.New("no field for columns " + strings.Join(extra, ", "))
+		}
+	}
+	return
+}
+
+// localSpec combines a field's tag settings with the options of this
+// ReadIter.
+func (this *ReadIter) localSpec(spec fieldSpec) fieldSpec {
+	spec.factory = this.factories[spec.field]
+	if this.thousands != 0 {
+		spec.thousands = this.thousands
+	} else if spec.thousandsTag {
+		spec.thousands = ','
+	}
+	spec.decimal = this.decimal
+	if spec.decimalTag != 0 {
+		spec.decimal = spec.decimalTag
+		// the field's own decimal point wins over a global separator
+		if spec.thousands == spec.decimal {
+			spec.thousands = 0
+		}
+	}
+	return spec
+}
+
+// UnmappedHeaders returns the header names that no struct field is mapped
+// to, in file order. It does not depend on WithStrictHeaders.
+func (this *ReadIter) UnmappedHeaders() []string {
+	return this.unmapped(make([]bool, len(this.Headers)))
+}
+
+// unmapped is UnmappedHeaders counting the columns marked in used as
+// mapped.
+func (this *ReadIter) unmapped(used []bool) (extra []string) {
+	for fi, ci := range this.tags {
+		if ci >= 0 && ci < len(used) {
+			used[ci] = true
+		}
+		for _, k := range this.more[fi] {
+			used[k] = true
+		}
+	}
+	for k, h := range this.Headers {
+		if !used[k] {
+			extra = append(extra, h)
+		}
+	}
+	return
+}
+
+// kindOf returns the conversion kind for a field of type t, or none_k.
+func kindOf(t reflect.Type) int {
+	// time.Time is also a TextUnmarshaler, but we want the format tag
+	if t == timeType {
+		return time_k
+	}
+	// a Duration is an int64 but is parsed as "1h30m"
+	if t == durationType {
+		return duration_k
+	}
+	if t == ipType {
+		return ip_k
+	}
+	if t.Kind() == reflect.Interface && t.Implements(valueType) {
+		return iface_k
+	}
+	// this is necessary because Kind can't tell distinguish between a primitive type
+	// and a type derived from it. We're looking for a TextUnmarshaler or Value
+	// interface defined on the pointer to this value
+	if reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return text_k
+	}
+	if reflect.PointerTo(t).Implements(valueWithErrorType) {
+		return verr_k
+	}
+	if reflect.PointerTo(t).Implements(valueType) {
+		return value_k
+	}
+	if t.ConvertibleTo(timeType) {
+		return time_k
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int16, reflect.Int8, reflect.Int32, reflect.Int64:
+		return int_k
+	case reflect.Uint, reflect.Uint16, reflect.Uint8, reflect.Uint32, reflect.Uint64:
+		return uint_k
+	case reflect.Float32, reflect.Float64:
+		return float_k
+	case reflect.String:
+		return string_k
+	case reflect.Bool:
+		return bool_k
+	case reflect.Slice:
+		switch kindOf(t.Elem()) {
+		case string_k, int_k, uint_k, float_k, bool_k, duration_k:
+			return sep_k
+		}
+	}
+	return none_k
+}
+
+// HeaderIndex returns the zero-based column of the header matching name,
+// compared as in field mapping, or -1 if there is none.
+func (this *ReadIter) HeaderIndex(name string) int {
+	for k, h := range this.Headers {
+		if this.sameName(h, name) {
+			return k
+		}
+	}
+	return -1
+}
+
+// sameName compares column names, ignoring case unless WithCaseSensitive.
+func (this *ReadIter) sameName(a, b string) bool {
+	return a == b || !this.caseSensitive && strings.EqualFold(a, b)
+}
+
+// isSelected reports whether column ci is to be read.
+func (this *ReadIter) isSelected(ci int) bool {
+	if len(this.selected) == 0 || ci >= len(this.Headers) {
+		return true
+	}
+	for _, name := range this.selected {
+		if this.sameName(this.Headers[ci], name) {
+			return true
+		}
+	}
+	return false
+}
+
+// removeBOM removes a byte order mark from the first header.
+func removeBOM(headers []string) {
+	if len(headers) > 0 {
+		headers[0] = strings.TrimPrefix(headers[0], "\ufeff")
 	}
-	fmt.Println(len(this.fields), len(this.kinds))
-	return this, err
 }
 
 // Creates a new iterator from a Reader source and a user-defined struct.
-func NewReadIter(rdr Reader, ps interface{}) (this *ReadIter, err error) {
-	lCsvHeaders, err := rdr.Read()
+// Options are applied before the header row is read.
+func NewReadIter(rdr Reader, ps interface{}, opts ...ReadIterOption) (this *ReadIter, err error) {
+	this = new(ReadIter)
+	this.Line = 1
+	this.progressEvery = DefaultProgressInterval
+	for _, opt := range opts {
+		opt(this)
+	}
+	if err = this.start(rdr, ps); err != nil {
+		this = nil
+	}
+	return
+}
 
-	// Remove BOM
-	if len(lCsvHeaders) > 0 {
-		lCsvHeaders[0] = strings.Trim(lCsvHeaders[0], "\xef\xbb\xbf")
+// Reset starts over with a new Reader source and user struct, keeping
+// the options. The header row is read again and Line is reset to 1.
+// Rewinding the underlying file, if needed, is left to the caller.
+func (this *ReadIter) Reset(rdr Reader, ps interface{}) error {
+	this.rewind()
+	this.unmap()
+	return this.start(rdr, ps)
+}
+
+// CloneIter returns an independent ReadIter at the same position, filling
+// its own copy of the user struct, which Target returns. The Reader must
+// be a BufferedReader, so that both can read the remaining rows; CloneIter
+// returns an error otherwise. Closing the clone closes its BufferedReader,
+// releasing the rows kept for it, and not the closer of this ReadIter.
+func (this *ReadIter) CloneIter() (*ReadIter, error) {
+	br, ok := this.Reader.(*BufferedReader)
+	if !ok {
+		return nil, errors.New("cannot clone a ReadIter without a BufferedReader")
+	}
+	c := new(ReadIter)
+	*c = *this
+	cbr := br.Clone()
+	c.Reader = cbr
+	c.closer = cbr
+	c.Headers = append([]string(nil), this.Headers...)
+	c.Errors = append([]error(nil), this.Errors...)
+	c.peekRow = append([]string(nil), this.peekRow...)
+	// the embedded structs must not be shared with this ReadIter
+	c.value = copyStruct(this.value)
+	transforms := this.transforms
+	c.unmap()
+	if err := c.mapType(c.value); err != nil {
+		return nil, err
 	}
+	for _, fns := range transforms {
+		c.transforms = append(c.transforms, append([]func(string) string(nil), fns...))
+	}
+	return c, nil
+}
+
+// Close closes the io.Closer given with WithCloser; without one it does
+// nothing.
+func (this *ReadIter) Close() error {
+	if this.closer == nil {
+		return nil
+	}
+	return this.closer.Close()
+}
+
+// Target returns the pointer to the user struct that Get fills.
+func (this *ReadIter) Target() interface{} {
+	return this.value.Addr().Interface()
+}
 
-	this, _ = mapType(lCsvHeaders, reflect.ValueOf(ps).Elem())
+// reuse is Reset for the same user struct, which must have been zeroed:
+// the fields are mapped again, but into the slices of the old mapping.
+func (this *ReadIter) reuse(rdr Reader) error {
+	this.rewind()
+	if err := this.readHeader(rdr); err != nil {
+		return err
+	}
+	// the fields of embedded pointers have to be allocated again
+	this.fields = this.fields[:0]
+	this.kinds = this.kinds[:0]
+	this.tags = this.tags[:0]
+	this.more = this.more[:0]
+	this.transforms = nil
+	this.specs = this.specs[:0]
+	return this.mapType(this.value)
+}
+
+// rewind clears the state of an iteration.
+func (this *ReadIter) rewind() {
+	this.Headers = nil
+	this.Error = nil
+	this.Errors = nil
+	this.Line = 1
+	this.Column = 0
+	this.RawRow = nil
+	this.peeked = false
+	this.peekRow = nil
+	this.peekErr = nil
+	this.rows = 0
+	this.returned = 0
+	this.skipped = false
+}
+
+// OnHeader passes the header row to fn and maps the fields again to the
+// headers that fn returns, which replace Headers. fn is kept and also
+// applied by Reset. Transforms added before are dropped, so call
+// AddTransform afterwards.
+func (this *ReadIter) OnHeader(fn func(headers []string) []string) error {
+	this.onHeader = fn
+	this.Headers = fn(this.Headers)
+	this.unmap()
+	return this.mapType(this.value)
+}
+
+// unmap forgets the fields mapped by mapType.
+func (this *ReadIter) unmap() {
+	this.fields = nil
+	this.kinds = nil
+	this.tags = nil
+	this.more = nil
+	this.transforms = nil
+	this.specs = nil
+}
+
+// start reads the header row from rdr and maps the fields of ps.
+func (this *ReadIter) start(rdr Reader, ps interface{}) error {
+	if pt := reflect.TypeOf(ps); pt == nil || pt.Kind() != reflect.Ptr || pt.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("ps must be a pointer to a struct, got %v", pt)
+	}
+	if reflect.ValueOf(ps).IsNil() {
+		return errors.New("ps must be a pointer to a struct, got a nil pointer")
+	}
+	if err := this.readHeader(rdr); err != nil {
+		return err
+	}
+	this.value = reflect.ValueOf(ps).Elem()
+	return this.mapType(this.value)
+}
+
+// readHeader makes rdr the source and reads its header row, if any.
+func (this *ReadIter) readHeader(rdr Reader) error {
 	this.Reader = rdr
-	if err != nil {
-		this = nil
-		return
+	if cr, ok := rdr.(*csv.Reader); ok {
+		this.configure(cr)
 	}
-	//fmt.Println(len(this.fields), len(this.kinds))
-	/*
-		st := reflect.TypeOf(ps).Elem()
-		sv := reflect.ValueOf(ps).Elem()
-		nf := st.NumField()
-		this.kinds = make([]int, nf)
-		this.tags = make([]int, nf)
-		this.fields = make([]reflect.Value, nf)
-		fmt.Println(this.fields, this.tags, this.kinds)
-		for i := 0; i < nf; i++ {
-			f := st.Field(i) //field
-
-			// ADD BY HZM
-			if f.Kind() == reflect.Struct {
-				lParentStruct := f.Type()
-				for _, lFld := range lParentStruct.NumField() {
-					col.FieldName = fmt.Sprintf("%v.%v", t.Field(i).Name, lFld.FieldName)
-					this.fields = append(this.fields)
-				}
 
-				continue
-			}
+	if this.noHeader {
+		// the first row is data
+		this.Line = 0
+	} else {
+		lCsvHeaders, err := rdr.Read()
+		if err != nil {
+			return err
+		}
 
-			fmt.Println(f)
-			val := sv.Field(i) // field value
-			// get the corresponding field name and look it up in the headers
-			tag := f.Tag.Get("field")
-			if len(tag) == 0 {
-				tag = f.Name
-				if strings.Contains(tag, "_") {
-					tag = strings.Replace(tag, "_", " ", -1)
-				}
+		if !this.keepBOM {
+			removeBOM(lCsvHeaders)
+		}
+		for k, h := range lCsvHeaders {
+			if name, ok := this.columnMap[h]; ok {
+				lCsvHeaders[k] = name
 			}
-
-			// 遍历对比
-			itag := -1
-			for k, h := range this.Headers {
-				if strings.EqualFold(h, tag) {
-					itag = k
-					break
-				}
+		}
+		if this.unicodeNorm != nil {
+			for k, h := range lCsvHeaders {
+				lCsvHeaders[k] = this.unicodeNorm(h)
 			}
-
-			// 判断是否有该Field
-			if itag == -1 {
-				continue
-				/*
-					err = errors.New("cannot find this field " + tag)
-					this = nil
-					return
-	*/
-	/*			}
-		kind := none_k
-		Kind := f.Type.Kind()
-		// this is necessary because Kind can't tell distinguish between a primitive type
-		// and a type derived from it. We're looking for a Value interface defined on
-		// the pointer to this value
-		_, ok := val.Addr().Interface().(Value)
-		if ok {
-			val = val.Addr()
-			kind = value_k
-		} else {
-			switch Kind {
-			case reflect.Int, reflect.Int16, reflect.Int8, reflect.Int32, reflect.Int64:
-				kind = int_k
-			case reflect.Uint, reflect.Uint16, reflect.Uint8, reflect.Uint32, reflect.Uint64:
-				kind = uint_k
-			case reflect.Float32, reflect.Float64:
-				kind = float_k
-			case reflect.String:
-				kind = string_k
-			default:
-				kind = value_k
-				_, ok := val.Interface().(Value)
-				if !ok {
-					err = errors.New("cannot convert this type ")
-					this = nil
-					return
-				}
+		}
+		if this.normalize != nil {
+			for k, h := range lCsvHeaders {
+				lCsvHeaders[k] = this.normalize(h)
 			}
 		}
-		this.kinds[i] = kind
-		this.tags[i] = itag
-		this.fields[i] = val
+		if this.onHeader != nil {
+			lCsvHeaders = this.onHeader(lCsvHeaders)
+		}
+		this.Headers = lCsvHeaders
 	}
-	*/
-	return
+	return nil
 }
 
 // The Get method reads the next row. If there was an error or EOF, it
 // will return false.  Client code must then check that ReadIter.Error is
 // not nil to distinguish between normal EOF and specific errors.
+// With WithSkipErrors, rows that fail to convert are collected in
+// ReadIter.Errors and skipped instead.
 func (this *ReadIter) Get() bool {
-	row, err := this.Reader.Read()
-	this.Line = this.Line + 1
-	if err != nil {
-		if err != io.EOF {
+	if this.limit > 0 && this.returned >= this.limit {
+		return false
+	}
+	if this.offset > 0 && !this.skipped {
+		this.skipped = true
+		if err := this.Skip(this.offset); err != nil {
+			if err != io.EOF {
+				this.Error = err
+			}
+			return false
+		}
+	}
+	for {
+		if this.ctx != nil {
+			if err := this.ctx.Err(); err != nil {
+				this.Error = err
+				return false
+			}
+		}
+		row, err := this.read()
+		this.Line = this.Line + 1
+		if err != nil {
+			if err != io.EOF {
+				this.Error = err
+			}
+			return false
+		}
+		this.rows = this.rows + 1
+		if this.progress != nil && this.Line%this.progressEvery == 0 {
+			this.progress(this.Line)
+		}
+		if this.beforeRow != nil {
+			if row = this.beforeRow(row); row == nil {
+				continue
+			}
+		}
+		this.RawRow = row
+		err = this.setRow(row)
+		if err == nil {
+			if c, ok := this.value.Addr().Interface().(Computed); ok {
+				c.Compute()
+			}
+			if this.afterRow != nil {
+				this.afterRow()
+			}
+			this.returned = this.returned + 1
+			return true
+		}
+		if this.onError != nil {
+			if err = this.onError(err, this.Line, row); err == nil {
+				this.Column = 0
+				continue
+			}
 			this.Error = err
+			return false
+		}
+		this.Error = err
+		if !this.skipErrors {
+			return false
+		}
+		this.Errors = append(this.Errors, err)
+		this.Column = 0
+	}
+}
+
+// OnBeforeRow has Get pass each row to fn as it is read; the row that fn
+// returns is used instead, and a nil result skips the row. Only the last
+// function set is kept.
+func (this *ReadIter) OnBeforeRow(fn func(row []string) []string) {
+	this.beforeRow = fn
+}
+
+// OnAfterRow has Get call fn once a row has been fully converted, just
+// before it returns true; rows that fail do not call it. Only the last
+// function set is kept.
+func (this *ReadIter) OnAfterRow(fn func()) {
+	this.afterRow = fn
+}
+
+// OnError has Get call fn when a row cannot be converted. If fn returns
+// nil the row is skipped; otherwise its error becomes ReadIter.Error and
+// Get returns false. It takes the place of WithSkipErrors.
+func (this *ReadIter) OnError(fn func(err error, line int, row []string) error) {
+	this.onError = fn
+}
+
+// Peek returns the next row without consuming it: the following Get or
+// Skip starts with the same row. Calling Peek again returns it again.
+func (this *ReadIter) Peek() ([]string, error) {
+	if !this.peeked {
+		this.peekRow, this.peekErr = this.Reader.Read()
+		this.peeked = true
+	}
+	return this.peekRow, this.peekErr
+}
+
+// read returns the next row, which may have been read already by Peek.
+func (this *ReadIter) read() ([]string, error) {
+	if this.peeked {
+		this.peeked = false
+		row, err := this.peekRow, this.peekErr
+		this.peekRow, this.peekErr = nil, nil
+		return row, err
+	}
+	return this.Reader.Read()
+}
+
+// Skip reads past the next n rows without converting them, e.g. to pass
+// over a row of units below the header. It returns the first read error,
+// which is io.EOF if the rows run out.
+func (this *ReadIter) Skip(n int) error {
+	for i := 0; i < n; i++ {
+		if _, err := this.read(); err != nil {
+			return err
+		}
+		this.Line = this.Line + 1
+		this.rows = this.rows + 1
+	}
+	return nil
+}
+
+// RowCount returns the number of data rows read so far, not counting the
+// header, whether they were converted, skipped or rejected.
+func (this *ReadIter) RowCount() int {
+	return this.rows
+}
+
+// ColumnCount returns the number of columns in the header row.
+func (this *ReadIter) ColumnCount() int {
+	return len(this.Headers)
+}
+
+// ForEach calls Get until the rows run out, calling fn after each row is
+// read. It stops early without error if fn returns false, and returns the
+// error that stopped Get, if any.
+func (this *ReadIter) ForEach(fn func() bool) error {
+	for this.Get() {
+		if !fn() {
+			return nil
 		}
-		return false
 	}
-	var ival int64
-	var fval float64
-	var uval uint64
-	var v Value
-	var ok bool
+	return this.Error
+}
+
+// GetAll reads the remaining rows and appends a copy of the struct for
+// each to the slice that dest points to, which may be a []T or a []*T for
+// the struct type T of the ReadIter. It returns the error that stopped
+// Get, if any.
+func (this *ReadIter) GetAll(dest interface{}) error {
+	dv := reflect.ValueOf(dest)
+	st := this.value.Type()
+	if dv.Kind() != reflect.Ptr || dv.Elem().Kind() != reflect.Slice {
+		return errors.New("GetAll needs a pointer to a slice of " + st.String())
+	}
+	sv := dv.Elem()
+	et := sv.Type().Elem()
+	if et != st && et != reflect.PointerTo(st) {
+		return errors.New("cannot store " + st.String() + " in a slice of " + et.String())
+	}
+	for this.Get() {
+		if c := copyStruct(this.value); et == st {
+			sv.Set(reflect.Append(sv, c))
+		} else {
+			sv.Set(reflect.Append(sv, c.Addr()))
+		}
+	}
+	return this.Error
+}
+
+// WithContext makes Get fail with the context's error once ctx is
+// cancelled or times out. It returns the ReadIter for chaining.
+func (this *ReadIter) WithContext(ctx context.Context) *ReadIter {
+	this.ctx = ctx
+	return this
+}
 
+// setRow assigns the cells of row to the mapped fields.
+func (this *ReadIter) setRow(row []string) error {
 	for fi, ci := range this.tags {
-		vals := row[ci] // string at column ci of current row
-		f := this.fields[fi]
-		switch this.kinds[fi] {
-		case string_k:
-			f.SetString(vals)
-		case int_k:
-			// HZM 空白Int字段
-			if vals == "" {
-				vals = "0"
-			}
-			ival, err = strconv.ParseInt(vals, 10, 0)
-			f.SetInt(ival)
-		case uint_k:
-			uval, err = strconv.ParseUint(vals, 10, 0)
-			f.SetUint(uval)
-		case float_k:
-			fval, err = strconv.ParseFloat(vals, 0)
-			f.SetFloat(fval)
-		case value_k:
-			v, ok = f.Interface().(Value)
-			if !ok {
-				err = errors.New("Not a Value object")
-				break
+		if len(this.more[fi]) > 0 {
+			if err := this.setColumns(row, fi, ci); err != nil {
+				return err
 			}
-			v.Set(vals)
+			continue
 		}
-		if err != nil {
+		vals := this.cell(row, fi, ci) // string at column ci of current row
+		if vals == "" && this.specs[fi].hasDef {
+			vals = this.specs[fi].def
+		}
+		if this.specs[fi].required && strings.TrimSpace(vals) == "" {
 			this.Column = ci + 1
-			this.Error = err
-			return false
+			return &RequiredError{Line: this.Line, Column: this.Column, FieldName: this.specs[fi].field}
+		}
+		if spec := &this.specs[fi]; spec.minLen > 0 || spec.maxLen >= 0 {
+			if n := utf8.RuneCountInString(vals); n < spec.minLen || spec.maxLen >= 0 && n > spec.maxLen {
+				this.Column = ci + 1
+				return &LengthError{Line: this.Line, Column: this.Column, FieldName: spec.field,
+					MinLen: spec.minLen, MaxLen: spec.maxLen, Length: n}
+			}
+		}
+		if re := this.specs[fi].regex; re != nil && vals != "" && !re.MatchString(vals) {
+			this.Column = ci + 1
+			return &RegexError{Line: this.Line, Column: this.Column, FieldName: this.specs[fi].field,
+				RawValue: vals, Pattern: re.String()}
+		}
+		if err := setValue(this.fields[fi], this.kinds[fi], &this.specs[fi], vals); err != nil {
+			this.Column = ci + 1
+			return &ParseError{Line: this.Line, Column: this.Column,
+				FieldName: this.specs[fi].field, RawValue: vals, Err: err}
+		}
+		if !this.inRange(fi) {
+			this.Column = ci + 1
+			spec := &this.specs[fi]
+			return &RangeError{Line: this.Line, Column: this.Column, FieldName: spec.field,
+				RawValue: vals, Min: spec.min, Max: spec.max, HasMin: spec.hasMin, HasMax: spec.hasMax}
+		}
+	}
+	return nil
+}
+
+// inRange reports whether the value of field fi is within the bounds of
+// its 'min' and 'max' tags. A nil pointer is not checked.
+func (this *ReadIter) inRange(fi int) bool {
+	spec := &this.specs[fi]
+	if !spec.hasMin && !spec.hasMax {
+		return true
+	}
+	f := this.fields[fi]
+	if spec.ptr {
+		if f.IsNil() {
+			return true
+		}
+		f = f.Elem()
+	}
+	var n float64
+	switch this.kinds[fi] {
+	case int_k:
+		n = float64(f.Int())
+	case uint_k:
+		n = float64(f.Uint())
+	case float_k:
+		n = f.Float()
+	}
+	return !(spec.hasMin && n < spec.min || spec.hasMax && n > spec.max)
+}
+
+// AddTransform registers fn to rewrite the cells of the named struct field
+// before they are converted, e.g. to turn "N/A" into "". Transforms of
+// the same field are applied in the order they were added. It is an error
+// if no column is mapped to the field.
+func (this *ReadIter) AddTransform(fieldName string, fn func(string) string) error {
+	for fi := range this.specs {
+		if this.specs[fi].field != fieldName {
+			continue
+		}
+		if this.transforms == nil {
+			this.transforms = make([][]func(string) string, len(this.specs))
+		}
+		this.transforms[fi] = append(this.transforms[fi], fn)
+		return nil
+	}
+	return errors.New("no mapped field " + fieldName)
+}
+
+// cell returns the cell of row at column ci for field fi, trimmed and
+// transformed if need be.
+func (this *ReadIter) cell(row []string, fi, ci int) string {
+	vals := ""
+	if ci >= 0 && ci < len(row) {
+		vals = row[ci]
+	}
+	if this.unicodeNorm != nil {
+		vals = this.unicodeNorm(vals)
+	}
+	trim := this.trimSpace
+	if this.specs[fi].hasTrim {
+		trim = this.specs[fi].trim
+	}
+	if trim {
+		vals = strings.TrimSpace(vals)
+	}
+	if fi < len(this.transforms) {
+		for _, fn := range this.transforms[fi] {
+			vals = fn(vals)
+		}
+	}
+	return vals
+}
+
+// setColumns fills the slice field fi from column ci and the further
+// columns with the same header. Empty cells are left out.
+func (this *ReadIter) setColumns(row []string, fi, ci int) error {
+	spec := &this.specs[fi]
+	var cells, parts []string
+	for _, k := range append([]int{ci}, this.more[fi]...) {
+		if vals := this.cell(row, fi, k); vals != "" {
+			cells = append(cells, vals)
+		}
+	}
+	if len(cells) == 0 && spec.hasDef {
+		cells = []string{spec.def}
+	}
+	if spec.required && len(cells) == 0 {
+		this.Column = ci + 1
+		return &RequiredError{Line: this.Line, Column: this.Column, FieldName: spec.field}
+	}
+	for _, vals := range cells {
+		if spec.sep == "" {
+			parts = append(parts, vals)
+		} else {
+			parts = append(parts, strings.Split(vals, spec.sep)...)
+		}
+	}
+	if err := setSlice(this.fields[fi], spec, parts); err != nil {
+		this.Column = ci + 1
+		return &ParseError{Line: this.Line, Column: this.Column,
+			FieldName: spec.field, RawValue: strings.Join(cells, ","), Err: err}
+	}
+	return nil
+}
+
+// setValue converts vals according to kind and stores it in f. A pointer
+// field is set to a newly allocated value, or to nil for an empty cell.
+func setValue(f reflect.Value, kind int, spec *fieldSpec, vals string) (err error) {
+	if spec.ptr {
+		if vals == "" {
+			f.Set(reflect.Zero(f.Type()))
+			return
+		}
+		p := reflect.New(f.Type().Elem())
+		target := p.Elem()
+		if onPointer(kind) {
+			target = p
+		}
+		if err = setElem(target, kind, spec, vals); err == nil {
+			f.Set(p)
+		}
+		return
+	}
+	return setElem(f, kind, spec, vals)
+}
+
+// setElem does the conversion for setValue once pointers are resolved.
+func setElem(f reflect.Value, kind int, spec *fieldSpec, vals string) (err error) {
+	switch kind {
+	case string_k:
+		f.SetString(vals)
+	case int_k:
+		// HZM 空白Int字段
+		if vals == "" {
+			vals = "0"
+		}
+		var ival int64
+		ival, err = strconv.ParseInt(spec.digits(vals), spec.intBase(), 0)
+		f.SetInt(ival)
+	case uint_k:
+		var uval uint64
+		uval, err = strconv.ParseUint(spec.digits(vals), spec.intBase(), 0)
+		f.SetUint(uval)
+	case float_k:
+		vals = spec.float(vals)
+		percent := spec.percent && strings.HasSuffix(vals, "%")
+		if percent {
+			vals = strings.TrimSpace(strings.TrimSuffix(vals, "%"))
+		}
+		var fval float64
+		fval, err = strconv.ParseFloat(vals, 0)
+		if percent {
+			fval = fval / 100
+		}
+		f.SetFloat(fval)
+	case bool_k:
+		var bval bool
+		bval, err = strconv.ParseBool(vals)
+		f.SetBool(bval)
+	case time_k:
+		// an empty cell is the zero time
+		if vals == "" {
+			f.Set(reflect.Zero(f.Type()))
+			break
+		}
+		var tval time.Time
+		switch spec.format {
+		case "epoch", "epoch_ms":
+			var n int64
+			if n, err = strconv.ParseInt(vals, 10, 64); err != nil {
+				break
+			}
+			if spec.format == "epoch" {
+				tval = time.Unix(n, 0).UTC()
+			} else {
+				tval = time.UnixMilli(n).UTC()
+			}
+		default:
+			tval, err = time.Parse(spec.format, vals)
+		}
+		f.Set(reflect.ValueOf(tval).Convert(f.Type()))
+	case value_k:
+		v, ok := f.Interface().(Value)
+		if !ok {
+			err = errors.New("Not a Value object")
+			break
+		}
+		if !v.Set(vals) {
+			err = &ValueError{Field: spec.field, Value: vals}
+		}
+	case sub_k:
+		// the keys are the headers of the fields of the inner struct
+		p := reflect.New(f.Type())
+		if vals != "" {
+			var rs *ReadIter
+			if rs, err = NewReadIter(SubReader(vals, spec.rechunk, spec.kvSep), p.Interface()); err != nil {
+				break
+			}
+			if !rs.Get() {
+				err = rs.Error
+				break
+			}
+		}
+		f.Set(p.Elem())
+	case verr_k:
+		err = f.Interface().(ValueWithError).Set(vals)
+	case iface_k:
+		v := spec.factory()
+		if !v.Set(vals) {
+			err = &ValueError{Field: spec.field, Value: vals}
+			break
+		}
+		rv := reflect.ValueOf(v)
+		if !rv.Type().AssignableTo(f.Type()) {
+			err = errors.New("factory made a " + rv.Type().String() + ", not a " + f.Type().String())
+			break
+		}
+		f.Set(rv)
+	case duration_k:
+		var dval time.Duration
+		if vals != "" {
+			dval, err = time.ParseDuration(vals)
+		}
+		f.SetInt(int64(dval))
+	case ip_k:
+		// an empty cell is a nil IP
+		var ip net.IP
+		if vals != "" {
+			if ip = net.ParseIP(vals); ip == nil {
+				err = errors.New("invalid IP address")
+			}
+		}
+		f.Set(reflect.ValueOf(ip))
+	case text_k:
+		err = f.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(vals))
+	case sep_k:
+		if vals == "" {
+			if spec.omitEmpty {
+				f.Set(reflect.Zero(f.Type()))
+			} else {
+				f.Set(reflect.MakeSlice(f.Type(), 0, 0))
+			}
+			break
+		}
+		parts := []string{vals}
+		if spec.sep != "" {
+			parts = strings.Split(vals, spec.sep)
+		}
+		err = setSlice(f, spec, parts)
+	}
+	return
+}
+
+// setSlice sets the slice f to the values converted from parts; no parts
+// gives an empty slice, or nil with omitempty.
+func setSlice(f reflect.Value, spec *fieldSpec, parts []string) error {
+	if len(parts) == 0 {
+		if spec.omitEmpty {
+			f.Set(reflect.Zero(f.Type()))
+		} else {
+			f.Set(reflect.MakeSlice(f.Type(), 0, 0))
+		}
+		return nil
+	}
+	s := reflect.MakeSlice(f.Type(), len(parts), len(parts))
+	for i, part := range parts {
+		if err := setElem(s.Index(i), spec.elemKind, spec, part); err != nil {
+			return fmt.Errorf("element %d: %w", i, err)
 		}
 	}
-	return true
+	f.Set(s)
+	return nil
 }
@@ -0,0 +1,166 @@
+package csvdata
+
+import (
+	"encoding/csv"
+	"strings"
+	"testing"
+)
+
+type EmbeddedX1 struct{ X, Y string }
+
+type EmbeddedX2 struct{ X string }
+
+func TestEmbeddedNameConflict(t *testing.T) {
+	var p struct {
+		EmbeddedX1
+		EmbeddedX2
+	}
+	rs, err := NewReadIter(csv.NewReader(strings.NewReader("X,Y\nx,y\n")), &p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rs.Get() {
+		t.Fatal(rs.Error)
+	}
+	if p.EmbeddedX1.X != "" || p.EmbeddedX2.X != "" || p.Y != "y" {
+		t.Fatalf("got %+v, want only Y filled", p)
+	}
+}
+
+func TestGetAllEmbeddedPointer(t *testing.T) {
+	rs, err := NewReadIter(testEmbeddedReader(), new(testEmbedded))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var vals []testEmbedded
+	if err := rs.GetAll(&vals); err != nil {
+		t.Fatal(err)
+	}
+	var all []*testEmbedded
+	for k := range vals {
+		all = append(all, &vals[k])
+	}
+	checkEmbedded(t, all)
+}
+
+func TestThousandsDefault(t *testing.T) {
+	var p struct {
+		Amount int `thousands:"true" default:"1,000"`
+	}
+	rs, err := NewReadIter(NewSliceReader([][]string{{"Amount"}, {"2,500"}, {""}}), &p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []int{2500, 1000} {
+		if !rs.Get() || p.Amount != want {
+			t.Fatalf("got %d, want %d (%v)", p.Amount, want, rs.Error)
+		}
+	}
+}
+
+func TestDecimalSeparatorDefault(t *testing.T) {
+	var p struct {
+		Rate float64 `default:"1,5"`
+	}
+	rs, err := NewReadIter(NewSliceReader([][]string{{"Rate"}, {"2,5"}, {""}}), &p, WithDecimalSeparator(','))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []float64{2.5, 1.5} {
+		if !rs.Get() || p.Rate != want {
+			t.Fatalf("got %v, want %v (%v)", p.Rate, want, rs.Error)
+		}
+	}
+	if _, err = NewReadIter(NewSliceReader([][]string{{"Rate"}}), &p); err == nil {
+		t.Fatal("default 1,5 passed without WithDecimalSeparator")
+	}
+}
+
+func TestSelectColumnsStrictHeaders(t *testing.T) {
+	var p struct{ A, B string }
+	rows := [][]string{{"A", "B"}, {"a", "b"}}
+	rs, err := NewReadIter(NewSliceReader(rows), &p, WithSelectColumns("A"), WithStrictHeaders(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rs.Get() || p.A != "a" || p.B != "" {
+		t.Fatalf("got %+v, %v", p, rs.Error)
+	}
+	rows = [][]string{{"A", "C"}, {"a", "c"}}
+	if _, err = NewReadIter(NewSliceReader(rows), &p, WithSelectColumns("A"), WithStrictHeaders(true)); err == nil {
+		t.Fatal("column C without a field passed")
+	}
+}
+
+func TestMultilineQuotedField(t *testing.T) {
+	var p struct{ A string }
+	rs, err := NewReadIter(csv.NewReader(strings.NewReader("A\n\"l1\r\nl2\"\n")), &p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rs.Get() {
+		t.Fatal(rs.Error)
+	}
+	if p.A != "l1\nl2" {
+		t.Fatalf("got %q, want %q", p.A, "l1\nl2")
+	}
+}
+
+func TestCloneIterEmbeddedPointer(t *testing.T) {
+	p := new(testEmbedded)
+	rs, err := NewReadIter(NewBufferedReader(testEmbeddedReader()), p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rs.Get() {
+		t.Fatal(rs.Error)
+	}
+	c, err := rs.CloneIter()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !c.Get() {
+		t.Fatal(c.Error)
+	}
+	q := c.Target().(*testEmbedded)
+	if p.Tag != "a" || p.Name != "x" || q.Tag != "b" || q.Name != "y" {
+		t.Fatalf("got %q %q and clone %q %q", p.Tag, p.Name, q.Tag, q.Name)
+	}
+	rs, err = NewReadIter(testEmbeddedReader(), p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rs.CloneIter(); err == nil {
+		t.Fatal("cloned a ReadIter without a BufferedReader")
+	}
+}
+
+type testCloser struct{ closed bool }
+
+func (this *testCloser) Close() error {
+	this.closed = true
+	return nil
+}
+
+func TestCloneIterClose(t *testing.T) {
+	closer := new(testCloser)
+	rs, err := NewReadIter(NewBufferedReader(testEmbeddedReader()), new(testEmbedded), WithCloser(closer))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := rs.CloneIter()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Close()
+	if closer.closed {
+		t.Fatal("closing the clone closed the source")
+	}
+	if !rs.Get() {
+		t.Fatal(rs.Error)
+	}
+	rs.Close()
+	if !closer.closed {
+		t.Fatal("source not closed")
+	}
+}
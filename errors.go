@@ -0,0 +1,105 @@
+package csvdata
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// A ParseError is returned by Get, in ReadIter.Error, when a cell cannot
+// be converted to the type of its field. Use errors.As to get at it.
+type ParseError struct {
+	Line      int    // line of the row, counting the header as line 1
+	Column    int    // column of the cell, starting at 1
+	FieldName string // name of the struct field
+	RawValue  string // the cell as read
+	Err       error  // the underlying conversion error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("line %d, column %d: cannot parse %q into field %s: %v",
+		e.Line, e.Column, e.RawValue, e.FieldName, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// A RequiredError is returned by Get when the cell of a field tagged
+// `required:"true"` is empty.
+type RequiredError struct {
+	Line      int
+	Column    int
+	FieldName string
+}
+
+func (e *RequiredError) Error() string {
+	return fmt.Sprintf("line %d, column %d: required field %s is empty", e.Line, e.Column, e.FieldName)
+}
+
+// A RangeError is returned by Get when a numeric field is outside the
+// bounds of its 'min' and 'max' tags.
+type RangeError struct {
+	Line      int
+	Column    int
+	FieldName string
+	RawValue  string // the cell, or the default that replaced it
+	Min, Max  float64
+	HasMin    bool
+	HasMax    bool
+}
+
+func (e *RangeError) Error() string {
+	bounds := "["
+	if e.HasMin {
+		bounds += strconv.FormatFloat(e.Min, 'g', -1, 64)
+	}
+	bounds += ", "
+	if e.HasMax {
+		bounds += strconv.FormatFloat(e.Max, 'g', -1, 64)
+	}
+	bounds += "]"
+	return fmt.Sprintf("line %d, column %d: %s for field %s is outside %s", e.Line, e.Column, e.RawValue, e.FieldName, bounds)
+}
+
+// A RegexError is returned by Get when the cell of a string field does
+// not match the pattern of its 'regex' tag.
+type RegexError struct {
+	Line      int
+	Column    int
+	FieldName string
+	RawValue  string
+	Pattern   string
+}
+
+func (e *RegexError) Error() string {
+	return fmt.Sprintf("line %d, column %d: %q for field %s does not match %s", e.Line, e.Column, e.RawValue, e.FieldName, e.Pattern)
+}
+
+// A LengthError is returned by Get when the cell of a string field is
+// shorter than its 'minlen' tag or longer than its 'maxlen' tag.
+type LengthError struct {
+	Line      int
+	Column    int
+	FieldName string
+	MinLen    int
+	MaxLen    int // -1 if there is no maximum
+	Length    int // length of the cell in characters
+}
+
+func (e *LengthError) Error() string {
+	if e.MaxLen < 0 {
+		return fmt.Sprintf("line %d, column %d: field %s has length %d, want at least %d", e.Line, e.Column, e.FieldName, e.Length, e.MinLen)
+	}
+	return fmt.Sprintf("line %d, column %d: field %s has length %d, want %d to %d", e.Line, e.Column, e.FieldName, e.Length, e.MinLen, e.MaxLen)
+}
+
+// A ValueError is the Err of a ParseError when the Set method of a Value
+// field returns false.
+type ValueError struct {
+	Field string
+	Value string
+}
+
+func (e *ValueError) Error() string {
+	return fmt.Sprintf("%s rejected %q", e.Field, e.Value)
+}
@@ -0,0 +1,102 @@
+package csvdata
+
+import (
+	"errors"
+	"testing"
+)
+
+// firstError returns the error of the first row of rows read into ps.
+func firstError(t *testing.T, ps interface{}, rows ...[]string) error {
+	t.Helper()
+	rs, err := NewReadIter(NewSliceReader(rows), ps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rs.Get() {
+		t.Fatal("Get did not fail")
+	}
+	return rs.Error
+}
+
+func TestRequiredError(t *testing.T) {
+	var p struct {
+		Name string
+		ID   string `required:"true"`
+	}
+	err := firstError(t, &p, []string{"Name", "ID"}, []string{"x", ""})
+	var re *RequiredError
+	if !errors.As(err, &re) || re.Line != 2 || re.Column != 2 || re.FieldName != "ID" {
+		t.Fatalf("got %#v", err)
+	}
+	if want := "line 2, column 2: required field ID is empty"; err.Error() != want {
+		t.Fatalf("got %q, want %q", err, want)
+	}
+	var q struct {
+		ID string `required:"true" default:"x"`
+	}
+	if _, err := NewReadIter(NewSliceReader([][]string{{"ID"}}), &q); err == nil {
+		t.Fatal("required with a default passed")
+	}
+}
+
+func TestRangeError(t *testing.T) {
+	var p struct {
+		Age int `min:"0" max:"150"`
+	}
+	err := firstError(t, &p, []string{"Age"}, []string{"200"})
+	var re *RangeError
+	if !errors.As(err, &re) || re.FieldName != "Age" || re.RawValue != "200" || re.Min != 0 || re.Max != 150 {
+		t.Fatalf("got %#v", err)
+	}
+	if want := "line 2, column 1: 200 for field Age is outside [0, 150]"; err.Error() != want {
+		t.Fatalf("got %q, want %q", err, want)
+	}
+	var q struct {
+		Ratio float64 `max:"1.5" default:"2"`
+	}
+	err = firstError(t, &q, []string{"Ratio"}, []string{""})
+	if want := "line 2, column 1: 2 for field Ratio is outside [, 1.5]"; err == nil || err.Error() != want {
+		t.Fatalf("got %v, want %q", err, want)
+	}
+}
+
+func TestRegexError(t *testing.T) {
+	var p struct {
+		Code string `regex:"^[A-Z]{2}[0-9]{4}$"`
+	}
+	err := firstError(t, &p, []string{"Code"}, []string{"ab12"})
+	var re *RegexError
+	if !errors.As(err, &re) || re.RawValue != "ab12" || re.Pattern != "^[A-Z]{2}[0-9]{4}$" {
+		t.Fatalf("got %#v", err)
+	}
+	if want := `line 2, column 1: "ab12" for field Code does not match ^[A-Z]{2}[0-9]{4}$`; err.Error() != want {
+		t.Fatalf("got %q, want %q", err, want)
+	}
+	var q struct {
+		Code string `regex:"["`
+	}
+	if _, err := NewReadIter(NewSliceReader([][]string{{"Code"}}), &q); err == nil {
+		t.Fatal("invalid regex passed")
+	}
+}
+
+func TestLengthError(t *testing.T) {
+	var p struct {
+		Country string `minlen:"2" maxlen:"2" trim:"true"`
+	}
+	err := firstError(t, &p, []string{"Country"}, []string{" usa "})
+	var le *LengthError
+	if !errors.As(err, &le) || le.MinLen != 2 || le.MaxLen != 2 || le.Length != 3 {
+		t.Fatalf("got %#v", err)
+	}
+	if want := "line 2, column 1: field Country has length 3, want 2 to 2"; err.Error() != want {
+		t.Fatalf("got %q, want %q", err, want)
+	}
+	var q struct {
+		Name string `minlen:"1"`
+	}
+	err = firstError(t, &q, []string{"Name"}, []string{""})
+	if want := "line 2, column 1: field Name has length 0, want at least 1"; err == nil || err.Error() != want {
+		t.Fatalf("got %v, want %q", err, want)
+	}
+}
@@ -0,0 +1,9 @@
+module github.com/hzmsrv/CSV2Struct
+
+go 1.26.0
+
+require (
+	github.com/klauspost/compress v1.20.1
+	golang.org/x/text v0.42.0
+	golang.org/x/time v0.16.0
+)
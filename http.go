@@ -0,0 +1,30 @@
+package csvdata
+
+import (
+	"compress/gzip"
+	"encoding/csv"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// NewHTTPReadIter is NewReadIter for the body of resp. A Content-Type of
+// text/tab-separated-values reads tab-separated cells, and a
+// Content-Encoding of gzip is decompressed. Closing the body is left to
+// the caller.
+func NewHTTPReadIter(resp *http.Response, ps interface{}, opts ...ReadIterOption) (*ReadIter, error) {
+	var body io.Reader = resp.Body
+	if strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		zr, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+		body = zr
+	}
+	cr := csv.NewReader(body)
+	if mt, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type")); err == nil && mt == "text/tab-separated-values" {
+		cr.Comma = '\t'
+	}
+	return NewReadIter(cr, ps, opts...)
+}
@@ -0,0 +1,105 @@
+package csvdata
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// jsonLinesReader reads one JSON object per line as a row.
+type jsonLinesReader struct {
+	rdr     *bufio.Reader
+	headers []string
+	pending [][]string // rows read but not yet returned
+	line    int
+}
+
+// JSONLinesReader returns a Reader for JSON Lines: the keys of the object
+// on the first line, in their order there, form the header row, and every
+// line gives a row of its values in header order. Strings are taken as
+// they are, numbers and booleans as written, null and missing keys as an
+// empty cell, and nested objects and arrays as their JSON text.
+func JSONLinesReader(r io.Reader) (Reader, error) {
+	this := &jsonLinesReader{rdr: bufio.NewReader(r)}
+	keys, values, err := this.next()
+	if err != nil {
+		return nil, err
+	}
+	this.headers = keys
+	this.pending = [][]string{keys, this.row(keys, values)}
+	return this, nil
+}
+
+func (this *jsonLinesReader) Read() ([]string, error) {
+	if len(this.pending) > 0 {
+		row := this.pending[0]
+		this.pending = this.pending[1:]
+		return row, nil
+	}
+	keys, values, err := this.next()
+	if err != nil {
+		return nil, err
+	}
+	return this.row(keys, values), nil
+}
+
+// row puts the values of an object in header order.
+func (this *jsonLinesReader) row(keys, values []string) []string {
+	row := make([]string, len(this.headers))
+	for k, key := range keys {
+		for ci, h := range this.headers {
+			if h == key {
+				row[ci] = values[k]
+				break
+			}
+		}
+	}
+	return row
+}
+
+// next parses the next non-blank line into the keys and values of its
+// object, keeping the order of the keys.
+func (this *jsonLinesReader) next() (keys, values []string, err error) {
+	var line string
+	for strings.TrimSpace(line) == "" {
+		if line, err = this.rdr.ReadString('\n'); err != nil && (err != io.EOF || line == "") {
+			return
+		}
+		this.line = this.line + 1
+	}
+	err = nil
+	dec := json.NewDecoder(strings.NewReader(line))
+	dec.UseNumber()
+	if tok, terr := dec.Token(); terr != nil || tok != json.Delim('{') {
+		return nil, nil, fmt.Errorf("line %d: not a JSON object", this.line)
+	}
+	for dec.More() {
+		tok, terr := dec.Token()
+		if terr != nil {
+			return nil, nil, fmt.Errorf("line %d: %v", this.line, terr)
+		}
+		var raw json.RawMessage
+		if terr = dec.Decode(&raw); terr != nil {
+			return nil, nil, fmt.Errorf("line %d: %v", this.line, terr)
+		}
+		keys = append(keys, tok.(string))
+		values = append(values, jsonCell(raw))
+	}
+	return
+}
+
+// jsonCell returns the cell for a JSON value.
+func jsonCell(raw json.RawMessage) string {
+	switch {
+	case bytes.Equal(raw, []byte("null")):
+		return ""
+	case len(raw) > 0 && raw[0] == '"':
+		var s string
+		json.Unmarshal(raw, &s)
+		return s
+	}
+	return string(raw)
+}
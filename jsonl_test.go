@@ -0,0 +1,44 @@
+package csvdata
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestJSONLinesReader(t *testing.T) {
+	const data = `{"id": 1, "name": "a", "ok": true, "tags": ["x", 2], "meta": {"k": null}}
+
+{"name": "b", "id": 2.5, "ok": null, "extra": 1}
+{"id": 3}
+`
+	rdr, err := JSONLinesReader(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		{"id", "name", "ok", "tags", "meta"},
+		{"1", "a", "true", `["x", 2]`, `{"k": null}`},
+		{"2.5", "b", "", "", ""}, // null and missing keys are empty
+		{"3", "", "", "", ""},
+	}
+	for _, w := range want {
+		row, err := rdr.Read()
+		if err != nil || !reflect.DeepEqual(row, w) {
+			t.Fatalf("got %q, %v, want %q", row, err, w)
+		}
+	}
+	if _, err := rdr.Read(); err != io.EOF {
+		t.Fatalf("got %v, want EOF", err)
+	}
+	rdr, err = JSONLinesReader(strings.NewReader("{\"a\": 1}\n[1]\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rdr.Read()
+	rdr.Read()
+	if _, err := rdr.Read(); err == nil {
+		t.Fatal("a JSON array passed as an object")
+	}
+}
@@ -0,0 +1,61 @@
+package csvdata
+
+import (
+	"encoding/json"
+	"os"
+)
+
+// FieldMapping gives the column names of struct fields outside the
+// struct tags, so that they can be changed without recompiling. Columns
+// maps Go field names to column names; a field listed there is matched
+// by that name alone, ignoring its 'field' and 'alias' tags.
+type FieldMapping struct {
+	Columns map[string]string
+}
+
+// LoadMapping reads a FieldMapping from a JSON file holding one object
+// from field names to column names, e.g. {"FirstName": "First Name"}.
+func LoadMapping(path string) (*FieldMapping, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	m := new(FieldMapping)
+	if err = json.Unmarshal(data, &m.Columns); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// column returns the column name of a field, if the mapping has one. It
+// may be called on a nil mapping.
+func (this *FieldMapping) column(field string) (string, bool) {
+	if this == nil {
+		return "", false
+	}
+	col, ok := this.Columns[field]
+	return col, ok
+}
+
+// Creates a new iterator like NewReadIter, with the column names of m
+// taking the place of the struct tags.
+func NewReadIterWithMapping(rdr Reader, ps interface{}, m *FieldMapping, opts ...ReadIterOption) (*ReadIter, error) {
+	opts = append(opts, func(this *ReadIter) {
+		this.mapping = m
+	})
+	return NewReadIter(rdr, ps, opts...)
+}
+
+// FieldMapping returns the mapping given to NewReadIterWithMapping, or
+// nil, to be passed on to WithFieldMapping.
+func (this *ReadIter) FieldMapping() *FieldMapping {
+	return this.mapping
+}
+
+// WithFieldMapping names the columns written by WriteIter after m rather
+// than the struct tags, as NewReadIterWithMapping reads them.
+func WithFieldMapping(m *FieldMapping) WriteIterOption {
+	return func(this *WriteIter) {
+		this.mapping = m
+	}
+}
@@ -0,0 +1,46 @@
+package csvdata
+
+import "io"
+
+// MapReadIter is an iterator for when no struct is known in advance: each
+// row is stored in a map from header name to cell.
+type MapReadIter struct {
+	Reader  Reader
+	Headers []string
+	Error   error
+	Line    int
+}
+
+// Creates a new map iterator from a Reader source; the header row is read
+// immediately.
+func NewMapReadIter(rdr Reader) (this *MapReadIter, err error) {
+	headers, err := rdr.Read()
+	if err != nil {
+		return
+	}
+	removeBOM(headers)
+	this = &MapReadIter{Reader: rdr, Headers: headers, Line: 1}
+	return
+}
+
+// The Get method clears m and fills it with the next row. As with
+// ReadIter.Get, false means EOF or an error in Error.
+func (this *MapReadIter) Get(m map[string]string) bool {
+	row, err := this.Reader.Read()
+	this.Line = this.Line + 1
+	if err != nil {
+		if err != io.EOF {
+			this.Error = err
+		}
+		return false
+	}
+	clear(m)
+	for k, h := range this.Headers {
+		if k < len(row) {
+			m[h] = row[k]
+		} else {
+			m[h] = ""
+		}
+	}
+	return true
+}
@@ -0,0 +1,59 @@
+package csvdata
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"reflect"
+)
+
+// MarshalCSV returns the cells of the struct ps, passed by value or as a
+// pointer, in the order of the header row that WriteIter would write.
+func MarshalCSV(ps interface{}) ([]string, error) {
+	st := reflect.TypeOf(ps)
+	if st != nil && st.Kind() == reflect.Ptr {
+		st = st.Elem()
+	}
+	if st == nil || st.Kind() != reflect.Struct {
+		return nil, errors.New("cannot marshal this type, need a struct")
+	}
+	wi := &WriteIter{typ: st}
+	if err := wi.mapType(st); err != nil {
+		return nil, err
+	}
+	return wi.row(ps)
+}
+
+// UnmarshalCSV fills the struct that ps points to from a row whose
+// columns are named by headers, as ReadIter.Get would.
+func UnmarshalCSV(row []string, headers []string, ps interface{}) error {
+	rs, err := NewReadIter(NewSliceReader([][]string{headers, row}), ps)
+	if err != nil {
+		return err
+	}
+	if !rs.Get() {
+		return rs.Error
+	}
+	return nil
+}
+
+// ToCSVRow returns the cells of the struct ps, without the header row. It
+// is MarshalCSV under the name used with ToCSVLine.
+func ToCSVRow(ps interface{}) ([]string, error) {
+	return MarshalCSV(ps)
+}
+
+// ToCSVLine writes the cells of the struct ps to w as one CSV line ending
+// in a newline, e.g. for a log encoder that has no use for a WriteIter.
+func ToCSVLine(ps interface{}, w io.Writer) error {
+	row, err := ToCSVRow(ps)
+	if err != nil {
+		return err
+	}
+	cw := csv.NewWriter(w)
+	if err = cw.Write(row); err != nil {
+		return err
+	}
+	cw.Flush()
+	return cw.Error()
+}
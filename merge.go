@@ -0,0 +1,65 @@
+package csvdata
+
+import "iter"
+
+// MergeSortedReadIters joins two iterators that are both sorted on their
+// key, yielding every pair for which cmp returns 0. cmp compares the key
+// of a left row with that of a right row, returning a negative number if
+// the left one sorts first. Rows with equal keys on both sides give every
+// combination. Reading stops at the first error; check the Error of
+// left and right afterwards.
+func MergeSortedReadIters[L, R any](left *ReadIterTyped[L], right *ReadIterTyped[R], cmp func(*L, *R) int) iter.Seq2[*L, *R] {
+	return func(yield func(*L, *R) bool) {
+		l, lok := left.Get()
+		r, rok := right.Get()
+		for lok && rok {
+			c := cmp(l, r)
+			if c < 0 {
+				l, lok = left.Get()
+				continue
+			}
+			if c > 0 {
+				r, rok = right.Get()
+				continue
+			}
+			// collect the run of right rows with this key
+			group := []*R{r}
+			for r, rok = right.Get(); rok && cmp(l, r) == 0; r, rok = right.Get() {
+				group = append(group, r)
+			}
+			for lok && cmp(l, group[0]) == 0 {
+				for _, g := range group {
+					if !yield(l, g) {
+						return
+					}
+				}
+				l, lok = left.Get()
+			}
+		}
+	}
+}
+
+// MergeHashReadIters joins two iterators in any order on the keys given
+// by leftKey and rightKey. The right side is read into memory first, then
+// the left side is streamed, yielding each left row with every right row
+// of the same key, in the order they were read. Check the Error of left
+// and right afterwards.
+func MergeHashReadIters[L, R any, K comparable](left *ReadIterTyped[L], right *ReadIterTyped[R], leftKey func(*L) K, rightKey func(*R) K) iter.Seq2[*L, *R] {
+	return func(yield func(*L, *R) bool) {
+		index := make(map[K][]*R)
+		for r, ok := right.Get(); ok; r, ok = right.Get() {
+			k := rightKey(r)
+			index[k] = append(index[k], r)
+		}
+		if right.Error != nil {
+			return
+		}
+		for l, ok := left.Get(); ok; l, ok = left.Get() {
+			for _, r := range index[leftKey(l)] {
+				if !yield(l, r) {
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package csvdata
+
+import (
+	"reflect"
+	"testing"
+)
+
+type mergeLeft struct {
+	Key int
+	L   string
+}
+
+type mergeRight struct {
+	Key int
+	R   string
+}
+
+func mergeInputs(t *testing.T) (*ReadIterTyped[mergeLeft], *ReadIterTyped[mergeRight]) {
+	t.Helper()
+	left, err := NewReadIterTyped[mergeLeft](NewSliceReader([][]string{
+		{"Key", "L"}, {"1", "a"}, {"2", "b"}, {"2", "c"}, {"3", "d"},
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	right, err := NewReadIterTyped[mergeRight](NewSliceReader([][]string{
+		{"Key", "R"}, {"2", "x"}, {"2", "y"}, {"3", "z"}, {"4", "w"},
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return left, right
+}
+
+// every combination of the rows with equal keys, in order
+var mergeWant = []string{"bx", "by", "cx", "cy", "dz"}
+
+func TestMergeSortedReadIters(t *testing.T) {
+	left, right := mergeInputs(t)
+	var got []string
+	for l, r := range MergeSortedReadIters(left, right, func(l *mergeLeft, r *mergeRight) int { return l.Key - r.Key }) {
+		got = append(got, l.L+r.R)
+	}
+	if !reflect.DeepEqual(got, mergeWant) {
+		t.Fatalf("got %q, want %q", got, mergeWant)
+	}
+}
+
+func TestMergeHashReadIters(t *testing.T) {
+	left, right := mergeInputs(t)
+	var got []string
+	for l, r := range MergeHashReadIters(left, right, func(l *mergeLeft) int { return l.Key }, func(r *mergeRight) int { return r.Key }) {
+		got = append(got, l.L+r.R)
+	}
+	if !reflect.DeepEqual(got, mergeWant) {
+		t.Fatalf("got %q, want %q", got, mergeWant)
+	}
+}
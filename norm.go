@@ -0,0 +1,13 @@
+package csvdata
+
+import "golang.org/x/text/unicode/norm"
+
+// WithUnicodeNorm applies the Unicode normalization form, such as
+// norm.NFC, to the header names before they are matched and to every
+// cell before it is converted, so that composed and decomposed accents
+// compare equal.
+func WithUnicodeNorm(form norm.Form) ReadIterOption {
+	return func(this *ReadIter) {
+		this.unicodeNorm = form.String
+	}
+}
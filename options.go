@@ -0,0 +1,220 @@
+package csvdata
+
+import (
+	"encoding/csv"
+	"io"
+)
+
+// A ReadIterOption configures a ReadIter; options are passed to
+// NewReadIter and applied before the header row is read.
+type ReadIterOption func(*ReadIter)
+
+// WithDelimiter sets the field delimiter of a *csv.Reader source.
+func WithDelimiter(r rune) ReadIterOption {
+	return func(this *ReadIter) {
+		this.delimiter = r
+	}
+}
+
+// WithComment sets the comment character of a *csv.Reader source;
+// lines beginning with it are ignored.
+func WithComment(r rune) ReadIterOption {
+	return func(this *ReadIter) {
+		this.comment = r
+	}
+}
+
+// WithTrimSpace strips leading and trailing white space from every cell
+// before it is converted.
+func WithTrimSpace(on bool) ReadIterOption {
+	return func(this *ReadIter) {
+		this.trimSpace = on
+	}
+}
+
+// WithCaseSensitive makes header matching case sensitive, so that columns
+// "name" and "Name" can be told apart. By default case is ignored.
+func WithCaseSensitive(on bool) ReadIterOption {
+	return func(this *ReadIter) {
+		this.caseSensitive = on
+	}
+}
+
+// WithLazyErrors relaxes the checks of a *csv.Reader source: quotes may
+// appear in unquoted fields and rows may have a varying number of fields.
+// Cells missing from a short row are read as empty.
+func WithLazyErrors(on bool) ReadIterOption {
+	return func(this *ReadIter) {
+		this.lazyErrors = on
+	}
+}
+
+// WithSkipErrors makes Get skip rows whose cells cannot be converted
+// rather than stop. The errors are collected in ReadIter.Errors and the
+// most recent one is also left in ReadIter.Error.
+func WithSkipErrors(on bool) ReadIterOption {
+	return func(this *ReadIter) {
+		this.skipErrors = on
+	}
+}
+
+// WithNoHeader is for files without a header row: every row is data, and
+// fields are mapped by their 'col' tag instead of by name.
+func WithNoHeader() ReadIterOption {
+	return func(this *ReadIter) {
+		this.noHeader = true
+	}
+}
+
+// WithStrictFields makes NewReadIter fail if any struct field has no
+// matching column, so that a renamed column is noticed at once. Fields
+// tagged `ignore:"true"` are exempt.
+func WithStrictFields(on bool) ReadIterOption {
+	return func(this *ReadIter) {
+		this.strictFields = on
+	}
+}
+
+// WithStrictHeaders makes NewReadIter fail if any column has no matching
+// struct field. Together with WithStrictFields it requires the file and
+// the struct to match one to one. A column left out by WithSelectColumns
+// passes if a field matches it.
+func WithStrictHeaders(on bool) ReadIterOption {
+	return func(this *ReadIter) {
+		this.strictHeaders = on
+	}
+}
+
+// WithThousandSeparator removes sep from the cells of all numeric fields
+// before they are parsed, e.g. ',' for "1,234,567". It also sets the
+// separator used by fields tagged `thousands:"true"`, which is ',' by
+// default.
+func WithThousandSeparator(sep rune) ReadIterOption {
+	return func(this *ReadIter) {
+		this.thousands = sep
+	}
+}
+
+// WithDecimalSeparator sets the decimal separator of float cells, e.g.
+// ',' for European files. A field's 'decimal' tag takes precedence.
+func WithDecimalSeparator(sep rune) ReadIterOption {
+	return func(this *ReadIter) {
+		this.decimal = sep
+	}
+}
+
+// WithSelectColumns reads only the named columns. Fields that match other
+// columns are left alone, keeping their zero value, which saves work on
+// wide files.
+func WithSelectColumns(names ...string) ReadIterOption {
+	return func(this *ReadIter) {
+		this.selected = names
+	}
+}
+
+// WithHeaderNormalizer applies fn to every name of the header row before
+// the fields are matched, e.g. strings.TrimSpace for padded names. The
+// normalized names are kept in ReadIter.Headers.
+func WithHeaderNormalizer(fn func(string) string) ReadIterOption {
+	return func(this *ReadIter) {
+		this.normalize = fn
+	}
+}
+
+// WithStripBOM(false) keeps a UTF-8 byte-order mark at the start of the
+// first header name, which is otherwise removed before matching.
+func WithStripBOM(strip bool) ReadIterOption {
+	return func(this *ReadIter) {
+		this.keepBOM = !strip
+	}
+}
+
+// WithJSONTagFallback(true) also matches a field by the name in its
+// 'json' tag, options stripped, when neither its column name nor its
+// aliases match a header.
+func WithJSONTagFallback(on bool) ReadIterOption {
+	return func(this *ReadIter) {
+		this.jsonFallback = on
+	}
+}
+
+// WithFieldFactory has Get fill the interface field named fieldName, of
+// type Value or an interface that includes it, with a value made by fn
+// and then Set from the cell. Such a field needs a factory to be read.
+func WithFieldFactory(fieldName string, fn func() Value) ReadIterOption {
+	return func(this *ReadIter) {
+		if this.factories == nil {
+			this.factories = make(map[string]func() Value)
+		}
+		this.factories[fieldName] = fn
+	}
+}
+
+// WithCloser has ReadIter.Close close c, typically the file or response
+// body the rows are read from.
+func WithCloser(c io.Closer) ReadIterOption {
+	return func(this *ReadIter) {
+		this.closer = c
+	}
+}
+
+// WithLimit makes Get return false, without an error, once it has
+// returned n rows; 0 means no limit.
+func WithLimit(n int) ReadIterOption {
+	return func(this *ReadIter) {
+		this.limit = n
+	}
+}
+
+// WithOffset makes the first Get skip n data rows, without converting
+// them, before reading one. With WithLimit it reads a page of a file.
+func WithOffset(n int) ReadIterOption {
+	return func(this *ReadIter) {
+		this.offset = n
+	}
+}
+
+// WithColumnMapper renames the columns of the header row before the
+// fields are matched: a header equal to a key of m is replaced by its
+// value, also in ReadIter.Headers.
+func WithColumnMapper(m map[string]string) ReadIterOption {
+	return func(this *ReadIter) {
+		this.columnMap = m
+	}
+}
+
+// DefaultProgressInterval is how many lines apart the function given to
+// WithProgressFunc is called, unless WithProgressInterval says otherwise.
+const DefaultProgressInterval = 1000
+
+// WithProgressFunc has Get call fn with the current line number every so
+// many lines. It is called from Get itself, so it needs no locking.
+func WithProgressFunc(fn func(line int)) ReadIterOption {
+	return func(this *ReadIter) {
+		this.progress = fn
+	}
+}
+
+// WithProgressInterval sets how many lines apart the progress function
+// is called.
+func WithProgressInterval(n int) ReadIterOption {
+	return func(this *ReadIter) {
+		if n > 0 {
+			this.progressEvery = n
+		}
+	}
+}
+
+// configure applies the options that belong to the csv.Reader itself.
+func (this *ReadIter) configure(cr *csv.Reader) {
+	if this.delimiter != 0 {
+		cr.Comma = this.delimiter
+	}
+	if this.comment != 0 {
+		cr.Comment = this.comment
+	}
+	if this.lazyErrors {
+		cr.LazyQuotes = true
+		cr.FieldsPerRecord = -1
+	}
+}
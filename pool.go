@@ -0,0 +1,48 @@
+package csvdata
+
+import (
+	"reflect"
+	"sync"
+)
+
+// ReadIterPool keeps ReadIters for the struct type T so that parsing many
+// small files does not set up a new iterator each time. An iterator from
+// the pool starts with a zero struct and no context; hooks set on it stay
+// with it.
+type ReadIterPool[T any] struct {
+	pool sync.Pool
+	opts []ReadIterOption
+}
+
+// Creates a new pool of iterators built with opts.
+func NewReadIterPool[T any](opts ...ReadIterOption) *ReadIterPool[T] {
+	return &ReadIterPool[T]{opts: opts}
+}
+
+// The Get method returns an iterator reading from rdr, after its header
+// row, into the struct returned by Struct.
+func (this *ReadIterPool[T]) Get(rdr Reader) (*ReadIter, error) {
+	if rs, ok := this.pool.Get().(*ReadIter); ok {
+		if err := rs.reuse(rdr); err != nil {
+			return nil, err
+		}
+		return rs, nil
+	}
+	return NewReadIter(rdr, new(T), this.opts...)
+}
+
+// Struct returns the struct that rs, an iterator from Get, fills.
+func (this *ReadIterPool[T]) Struct(rs *ReadIter) *T {
+	return rs.value.Addr().Interface().(*T)
+}
+
+// Put returns rs to the pool once it is no longer used. The struct it
+// fills is zeroed and the context of WithContext dropped.
+func (this *ReadIterPool[T]) Put(rs *ReadIter) {
+	rs.Reader = nil
+	rs.RawRow = nil
+	rs.peekRow = nil
+	rs.ctx = nil
+	rs.value.Set(reflect.Zero(rs.value.Type()))
+	this.pool.Put(rs)
+}
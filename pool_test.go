@@ -0,0 +1,51 @@
+package csvdata
+
+import (
+	"context"
+	"testing"
+)
+
+func TestReadIterPoolReuse(t *testing.T) {
+	type row struct{ A, B string }
+	pool := NewReadIterPool[row]()
+	rs, err := pool.Get(NewSliceReader([][]string{{"A", "B"}, {"a1", "secret"}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	rs.WithContext(ctx)
+	if !rs.Get() {
+		t.Fatal(rs.Error)
+	}
+	cancel()
+	pool.Put(rs)
+	// what pool.Get does with an iterator from the pool, which sync.Pool
+	// does not promise to return
+	if err := rs.reuse(NewSliceReader([][]string{{"A"}, {"a2"}})); err != nil {
+		t.Fatal(err)
+	}
+	if !rs.Get() {
+		t.Fatal(rs.Error)
+	}
+	if p := pool.Struct(rs); *p != (row{A: "a2"}) {
+		t.Fatalf("got %+v, want {A:a2 B:}", *p)
+	}
+}
+
+func TestReadIterPoolEmbeddedPointer(t *testing.T) {
+	pool := NewReadIterPool[testEmbedded]()
+	rs, err := pool.Get(testEmbeddedReader())
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool.Put(rs)
+	if err := rs.reuse(testEmbeddedReader()); err != nil {
+		t.Fatal(err)
+	}
+	if !rs.Get() {
+		t.Fatal(rs.Error)
+	}
+	if p := pool.Struct(rs); p.EmbeddedMeta == nil || p.Tag != "a" {
+		t.Fatalf("embedded field not filled: %+v", p)
+	}
+}
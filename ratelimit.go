@@ -0,0 +1,47 @@
+package csvdata
+
+import (
+	"context"
+
+	"golang.org/x/time/rate"
+)
+
+// rateLimitedReader spaces out the rows of its source.
+type rateLimitedReader struct {
+	rdr     Reader
+	limiter *rate.Limiter
+	ctx     context.Context
+}
+
+// A RateLimitOption configures NewRateLimitedReader.
+type RateLimitOption func(*rateLimitedReader)
+
+// WithWaitContext ends a wait in Read with the context's error once ctx is
+// cancelled or times out, e.g. the context given to ReadIter.WithContext.
+func WithWaitContext(ctx context.Context) RateLimitOption {
+	return func(this *rateLimitedReader) {
+		this.ctx = ctx
+	}
+}
+
+// NewRateLimitedReader returns a Reader that passes on the rows of rdr at
+// no more than rowsPerSecond, sleeping in Read as needed; 0 or less means
+// no limit.
+func NewRateLimitedReader(rdr Reader, rowsPerSecond float64, opts ...RateLimitOption) Reader {
+	limit := rate.Inf
+	if rowsPerSecond > 0 {
+		limit = rate.Limit(rowsPerSecond)
+	}
+	this := &rateLimitedReader{rdr: rdr, limiter: rate.NewLimiter(limit, 1), ctx: context.Background()}
+	for _, opt := range opts {
+		opt(this)
+	}
+	return this
+}
+
+func (this *rateLimitedReader) Read() ([]string, error) {
+	if err := this.limiter.Wait(this.ctx); err != nil {
+		return nil, err
+	}
+	return this.rdr.Read()
+}
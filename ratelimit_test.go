@@ -0,0 +1,24 @@
+package csvdata
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRateLimitedReaderCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	rows := [][]string{{"A"}, {"a"}, {"b"}}
+	rdr := NewRateLimitedReader(NewSliceReader(rows), 0.01, WithWaitContext(ctx))
+	if _, err := rdr.Read(); err != nil {
+		t.Fatal(err)
+	}
+	time.AfterFunc(20*time.Millisecond, cancel)
+	start := time.Now()
+	if _, err := rdr.Read(); err == nil {
+		t.Fatal("Read did not fail after cancel")
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Fatalf("Read returned %v after cancel", d)
+	}
+}
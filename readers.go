@@ -0,0 +1,611 @@
+package csvdata
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// multiReader reads its sources one after the other.
+type multiReader struct {
+	readers []Reader
+	header  bool // a header row has been returned
+	started bool // readers[0] has returned a row
+}
+
+// NewMultiReader returns a Reader that concatenates the rows of several
+// sources with the same columns. The header row is taken from the first
+// source that has one; the first row of every later source is assumed to
+// be the same header and is dropped.
+func NewMultiReader(readers ...Reader) Reader {
+	return &multiReader{readers: readers}
+}
+
+func (this *multiReader) Read() ([]string, error) {
+	for len(this.readers) > 0 {
+		row, err := this.readers[0].Read()
+		if err == io.EOF {
+			this.readers = this.readers[1:]
+			this.started = false
+			continue
+		}
+		if err != nil {
+			return row, err
+		}
+		if !this.started {
+			this.started = true
+			if this.header {
+				continue
+			}
+			this.header = true
+		}
+		return row, nil
+	}
+	return nil, io.EOF
+}
+
+// sliceReader reads rows from memory.
+type sliceReader struct {
+	rows [][]string
+}
+
+// NewSliceReader returns a Reader over rows held in memory, handy for
+// tests. As with a CSV file, the first row is the header.
+func NewSliceReader(rows [][]string) Reader {
+	return &sliceReader{rows: rows}
+}
+
+func (this *sliceReader) Read() ([]string, error) {
+	if len(this.rows) == 0 {
+		return nil, io.EOF
+	}
+	// a copy, since the caller may modify the row
+	row := append([]string(nil), this.rows[0]...)
+	this.rows = this.rows[1:]
+	return row, nil
+}
+
+// NewTSVReadIter is NewReadIter for a tab-separated file read from r.
+// Quotes are taken literally, as most TSV producers do not escape them.
+func NewTSVReadIter(r io.Reader, ps interface{}, opts ...ReadIterOption) (*ReadIter, error) {
+	cr := csv.NewReader(r)
+	cr.Comma = '\t'
+	cr.LazyQuotes = true
+	return NewReadIter(cr, ps, opts...)
+}
+
+// NewPipeReadIter is NewReadIter for a file read from r whose cells are
+// separated by '|'.
+func NewPipeReadIter(r io.Reader, ps interface{}, opts ...ReadIterOption) (*ReadIter, error) {
+	cr := csv.NewReader(r)
+	cr.Comma = '|'
+	return NewReadIter(cr, ps, opts...)
+}
+
+// FixedWidthReader is a Reader for files whose columns have fixed widths
+// rather than separators. Each line is cut into cells of the given widths,
+// counted in characters, and the padding around each cell is removed.
+type FixedWidthReader struct {
+	rdr     *bufio.Reader
+	widths  []int
+	headers []string
+}
+
+// NewFixedWidthReader returns a FixedWidthReader cutting the lines of r at
+// widths. If headers is nil the first line holds the column names;
+// otherwise headers is returned as the first row. Every width must be at
+// least 1.
+func NewFixedWidthReader(r io.Reader, widths []int, headers []string) (*FixedWidthReader, error) {
+	for _, w := range widths {
+		if w < 1 {
+			return nil, fmt.Errorf("invalid column width %d", w)
+		}
+	}
+	return &FixedWidthReader{rdr: bufio.NewReader(r), widths: widths, headers: headers}, nil
+}
+
+func (this *FixedWidthReader) Read() ([]string, error) {
+	if this.headers != nil {
+		row := this.headers
+		this.headers = nil
+		return row, nil
+	}
+	line, err := this.rdr.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return nil, err
+	}
+	line = strings.TrimRight(line, "\r\n")
+	chars := []rune(line)
+	row := make([]string, len(this.widths))
+	pos := 0
+	for k, w := range this.widths {
+		end := pos + w
+		if end > len(chars) {
+			end = len(chars)
+		}
+		if pos < end {
+			row[k] = strings.TrimSpace(string(chars[pos:end]))
+		}
+		pos = end
+	}
+	return row, nil
+}
+
+// rowBuffer holds the rows of a source shared by several BufferedReaders.
+type rowBuffer struct {
+	src  Reader
+	rows [][]string // rows from number base on
+	base int
+	err  error                    // error that ended the source
+	live map[*BufferedReader]bool // the readers not closed
+}
+
+// trim drops the rows that every live reader has read.
+func (this *rowBuffer) trim() {
+	low := -1
+	for br := range this.live {
+		if low < 0 || br.pos < low {
+			low = br.pos
+		}
+	}
+	n := low - this.base
+	if low < 0 || n > len(this.rows) {
+		n = len(this.rows)
+	}
+	if n <= 0 {
+		return
+	}
+	clear(this.rows[:n])
+	this.rows = this.rows[n:]
+	this.base = this.base + n
+}
+
+// BufferedReader wraps a Reader so that it can be cloned: each clone
+// reads the remaining rows independently of the others. The rows that a
+// reader is yet to read are kept in memory until it reads them or is
+// closed, so a clone that is no longer needed should be closed.
+type BufferedReader struct {
+	buf    *rowBuffer
+	pos    int // number of the next row
+	closed bool
+}
+
+// NewBufferedReader returns a BufferedReader reading from rdr.
+func NewBufferedReader(rdr Reader) *BufferedReader {
+	this := &BufferedReader{buf: &rowBuffer{src: rdr, live: make(map[*BufferedReader]bool)}}
+	this.buf.live[this] = true
+	return this
+}
+
+func (this *BufferedReader) Read() ([]string, error) {
+	if this.closed {
+		return nil, errors.New("read from a closed BufferedReader")
+	}
+	buf := this.buf
+	if k := this.pos - buf.base; k < len(buf.rows) {
+		this.pos = this.pos + 1
+		row := buf.rows[k]
+		buf.trim()
+		return row, nil
+	}
+	if buf.err != nil {
+		return nil, buf.err
+	}
+	row, err := buf.src.Read()
+	if err != nil {
+		buf.err = err
+		return nil, err
+	}
+	if len(buf.live) > 1 {
+		buf.rows = append(buf.rows, row)
+	} else {
+		buf.base = buf.base + 1
+	}
+	this.pos = this.pos + 1
+	return row, nil
+}
+
+// Clone returns a BufferedReader at the same position.
+func (this *BufferedReader) Clone() *BufferedReader {
+	c := &BufferedReader{buf: this.buf, pos: this.pos}
+	this.buf.live[c] = true
+	return c
+}
+
+// Close releases the rows kept for this reader, which cannot be read from
+// again; the source is not closed.
+func (this *BufferedReader) Close() error {
+	if !this.closed {
+		this.closed = true
+		delete(this.buf.live, this)
+		this.buf.trim()
+	}
+	return nil
+}
+
+// columnIndex returns the column of headers named name, ignoring case and
+// a byte order mark, or -1.
+func columnIndex(headers []string, name string) int {
+	for k, h := range headers {
+		if strings.EqualFold(strings.TrimPrefix(h, "\ufeff"), name) {
+			return k
+		}
+	}
+	return -1
+}
+
+// ColumnReader reads the header row of rdr and returns a function that
+// returns the cell of the named column in each following row, then
+// io.EOF. The name is compared ignoring case, as in field mapping.
+func ColumnReader(rdr Reader, header string) (func() (string, error), error) {
+	headers, err := rdr.Read()
+	if err != nil {
+		return nil, err
+	}
+	ci := columnIndex(headers, header)
+	if ci < 0 {
+		return nil, errors.New("no column " + header)
+	}
+	return func() (string, error) {
+		row, err := rdr.Read()
+		if err != nil {
+			return "", err
+		}
+		if ci < len(row) {
+			return row[ci], nil
+		}
+		return "", nil
+	}, nil
+}
+
+// rowTransformer applies functions to the cells of named columns.
+type rowTransformer struct {
+	rdr        Reader
+	transforms map[string]func(string) string
+	cols       map[int]func(string) string // nil until the header is read
+}
+
+// RowTransformer returns a Reader that passes the header row of rdr on
+// unchanged and applies, in every later row, the function given by
+// transforms for each column name, compared ignoring case.
+func RowTransformer(rdr Reader, transforms map[string]func(string) string) Reader {
+	return &rowTransformer{rdr: rdr, transforms: transforms}
+}
+
+func (this *rowTransformer) Read() ([]string, error) {
+	row, err := this.rdr.Read()
+	if err != nil {
+		return nil, err
+	}
+	if this.cols == nil {
+		this.cols = make(map[int]func(string) string)
+		for name, fn := range this.transforms {
+			if ci := columnIndex(row, name); ci >= 0 {
+				this.cols[ci] = fn
+			}
+		}
+		return row, nil
+	}
+	out := append([]string(nil), row...)
+	for k, fn := range this.cols {
+		if k < len(out) {
+			out[k] = fn(out[k])
+		}
+	}
+	return out, nil
+}
+
+// samplingReader returns a random sample of the rows of its source.
+type samplingReader struct {
+	rdr       Reader
+	k         int
+	rnd       *rand.Rand
+	headerOut bool
+	sampled   bool
+	rows      [][]string
+}
+
+// NewSamplingReader returns a Reader yielding the header row of rdr and
+// then k of its other rows chosen at random with reservoir sampling,
+// using seed for repeatable samples. The whole source is read on the first
+// data row, but only k rows are kept in memory.
+func NewSamplingReader(rdr Reader, k int, seed int64) Reader {
+	return &samplingReader{rdr: rdr, k: k, rnd: rand.New(rand.NewSource(seed))}
+}
+
+func (this *samplingReader) Read() ([]string, error) {
+	if !this.headerOut {
+		this.headerOut = true
+		return this.rdr.Read()
+	}
+	if !this.sampled {
+		this.sampled = true
+		if err := this.sample(); err != nil {
+			return nil, err
+		}
+	}
+	if len(this.rows) == 0 {
+		return nil, io.EOF
+	}
+	row := this.rows[0]
+	this.rows = this.rows[1:]
+	return row, nil
+}
+
+// sample fills the reservoir (Algorithm R).
+func (this *samplingReader) sample() error {
+	for n := 0; ; n++ {
+		row, err := this.rdr.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if n < this.k {
+			this.rows = append(this.rows, append([]string(nil), row...))
+		} else if j := this.rnd.Int63n(int64(n + 1)); j < int64(this.k) {
+			this.rows[j] = append([]string(nil), row...)
+		}
+	}
+}
+
+// dedupReader drops rows whose key has been seen before.
+type dedupReader struct {
+	rdr     Reader
+	keys    []string
+	cols    []int // nil until the header is read
+	seen    map[string]struct{}
+	limit   int
+	started bool
+}
+
+// A DedupOption configures the Reader made by NewDedupReader.
+type DedupOption func(*dedupReader)
+
+// WithDedupLimit caps the number of keys remembered at n; when the cap is
+// reached the keys seen so far are forgotten, so that a duplicate far
+// from its original may get through.
+func WithDedupLimit(n int) DedupOption {
+	return func(this *dedupReader) {
+		this.limit = n
+	}
+}
+
+// NewDedupReader returns a Reader that passes on the header row of rdr
+// and then only the first row for each value of the key columns, named
+// as in the header ignoring case. Without key columns the whole row is the
+// key.
+func NewDedupReader(rdr Reader, keyColumns []string, opts ...DedupOption) Reader {
+	this := &dedupReader{rdr: rdr, keys: keyColumns, seen: make(map[string]struct{})}
+	for _, opt := range opts {
+		opt(this)
+	}
+	return this
+}
+
+func (this *dedupReader) Read() ([]string, error) {
+	if !this.started {
+		this.started = true
+		row, err := this.rdr.Read()
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range this.keys {
+			ci := columnIndex(row, key)
+			if ci < 0 {
+				return nil, errors.New("no column " + key)
+			}
+			this.cols = append(this.cols, ci)
+		}
+		return row, nil
+	}
+	for {
+		row, err := this.rdr.Read()
+		if err != nil {
+			return nil, err
+		}
+		key := this.key(row)
+		if _, dup := this.seen[key]; dup {
+			continue
+		}
+		if this.limit > 0 && len(this.seen) >= this.limit {
+			this.seen = make(map[string]struct{})
+		}
+		this.seen[key] = struct{}{}
+		return row, nil
+	}
+}
+
+// key returns the composite key of row.
+func (this *dedupReader) key(row []string) string {
+	if len(this.cols) == 0 {
+		return strings.Join(row, "\x00")
+	}
+	parts := make([]string, len(this.cols))
+	for i, ci := range this.cols {
+		if ci < len(row) {
+			parts[i] = row[ci]
+		}
+	}
+	return strings.Join(parts, "\x00")
+}
+
+// sortReader returns the rows of its source sorted on one column.
+type sortReader struct {
+	rdr       Reader
+	column    string
+	ascending bool
+	numeric   bool
+	started   bool
+	rows      [][]string
+}
+
+// A SortOption configures the Reader made by NewSortReader.
+type SortOption func(*sortReader)
+
+// WithNumericSort(true) compares the cells as numbers; cells that are not
+// numbers come after the others.
+func WithNumericSort(on bool) SortOption {
+	return func(this *sortReader) {
+		this.numeric = on
+	}
+}
+
+// NewSortReader returns a Reader that passes on the header row of rdr and
+// then its other rows sorted on the named column, as strings unless
+// WithNumericSort is given. Rows with equal cells keep their order. All
+// rows are read into memory on the first data row.
+func NewSortReader(rdr Reader, column string, ascending bool, opts ...SortOption) Reader {
+	this := &sortReader{rdr: rdr, column: column, ascending: ascending}
+	for _, opt := range opts {
+		opt(this)
+	}
+	return this
+}
+
+func (this *sortReader) Read() ([]string, error) {
+	if !this.started {
+		this.started = true
+		header, err := this.rdr.Read()
+		if err != nil {
+			return nil, err
+		}
+		ci := columnIndex(header, this.column)
+		if ci < 0 {
+			return nil, errors.New("no column " + this.column)
+		}
+		if err = this.sortRows(ci); err != nil {
+			return nil, err
+		}
+		return header, nil
+	}
+	if len(this.rows) == 0 {
+		return nil, io.EOF
+	}
+	row := this.rows[0]
+	this.rows = this.rows[1:]
+	return row, nil
+}
+
+// sortRows reads the remaining rows and sorts them on column ci.
+func (this *sortReader) sortRows(ci int) error {
+	for {
+		row, err := this.rdr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		this.rows = append(this.rows, append([]string(nil), row...))
+	}
+	cell := func(row []string) string {
+		if ci < len(row) {
+			return row[ci]
+		}
+		return ""
+	}
+	sort.SliceStable(this.rows, func(i, j int) bool {
+		a, b := cell(this.rows[i]), cell(this.rows[j])
+		if !this.ascending {
+			a, b = b, a
+		}
+		if this.numeric {
+			fa, erra := strconv.ParseFloat(a, 64)
+			fb, errb := strconv.ParseFloat(b, 64)
+			switch {
+			case erra == nil && errb == nil:
+				return fa < fb
+			case erra == nil || errb == nil:
+				// numbers first, whatever the direction
+				return (erra == nil) == this.ascending
+			}
+		}
+		return a < b
+	})
+	return nil
+}
+
+// GroupReader reads batches of consecutive rows with the same key.
+type GroupReader struct {
+	Headers []string // the header row, once ReadGroup has been called
+
+	rdr     Reader
+	column  string
+	ci      int
+	next    []string // first row of the next group
+	nextErr error
+}
+
+// NewGroupReader returns a GroupReader over rdr, whose header row names
+// the key column.
+func NewGroupReader(rdr Reader, keyColumn string) *GroupReader {
+	return &GroupReader{rdr: rdr, column: keyColumn, ci: -1}
+}
+
+// ReadGroup returns the next run of rows that have the same cell in the
+// key column, or io.EOF after the last one. The rows should be sorted on
+// the key, e.g. with NewSortReader, for each key to form one group.
+func (this *GroupReader) ReadGroup() ([][]string, error) {
+	if this.Headers == nil {
+		header, err := this.rdr.Read()
+		if err != nil {
+			return nil, err
+		}
+		if this.ci = columnIndex(header, this.column); this.ci < 0 {
+			return nil, errors.New("no column " + this.column)
+		}
+		this.Headers = header
+		this.next, this.nextErr = this.read()
+	}
+	if this.next == nil {
+		return nil, this.nextErr
+	}
+	group := [][]string{this.next}
+	key := this.key(this.next)
+	for {
+		this.next, this.nextErr = this.read()
+		if this.next == nil || this.key(this.next) != key {
+			return group, nil
+		}
+		group = append(group, this.next)
+	}
+}
+
+// read returns a copy of the next row, or nil and the error.
+func (this *GroupReader) read() ([]string, error) {
+	row, err := this.rdr.Read()
+	if err != nil {
+		return nil, err
+	}
+	return append([]string(nil), row...), nil
+}
+
+// key returns the key cell of row.
+func (this *GroupReader) key(row []string) string {
+	if this.ci < len(row) {
+		return row[this.ci]
+	}
+	return ""
+}
+
+// SubReader returns a Reader over a cell holding key and value pairs, such
+// as "k1=v1;k2=v2" with sep ';' and kvSep '=': the keys form the header
+// row and the values the only data row. A pair without kvSep has an empty
+// value.
+func SubReader(cell string, sep rune, kvSep rune) Reader {
+	var keys, values []string
+	for _, pair := range strings.Split(cell, string(sep)) {
+		key, value, _ := strings.Cut(pair, string(kvSep))
+		keys = append(keys, strings.TrimSpace(key))
+		values = append(values, value)
+	}
+	return NewSliceReader([][]string{keys, values})
+}
@@ -0,0 +1,172 @@
+package csvdata
+
+import (
+	"encoding/csv"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestMultiReaderEmptySource(t *testing.T) {
+	rdr := NewMultiReader(
+		csv.NewReader(strings.NewReader("")),
+		csv.NewReader(strings.NewReader("A\na\n")),
+		csv.NewReader(strings.NewReader("A\nb\n")),
+	)
+	var got [][]string
+	for {
+		row, err := rdr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, row)
+	}
+	if want := [][]string{{"A"}, {"a"}, {"b"}}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestFixedWidthReader(t *testing.T) {
+	rdr, err := NewFixedWidthReader(strings.NewReader("ab  cd\nx   yz\n"), []int{4, 2}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range [][]string{{"ab", "cd"}, {"x", "yz"}} {
+		if row, err := rdr.Read(); err != nil || !reflect.DeepEqual(row, want) {
+			t.Fatalf("got %q, %v, want %q", row, err, want)
+		}
+	}
+	if _, err := NewFixedWidthReader(strings.NewReader(""), []int{2, -2}, nil); err == nil {
+		t.Fatal("negative width passed")
+	}
+}
+
+func TestBufferedReaderClose(t *testing.T) {
+	var rows [][]string
+	for i := 0; i < 10; i++ {
+		rows = append(rows, []string{string(rune('a' + i))})
+	}
+	br := NewBufferedReader(NewSliceReader(rows))
+	br.Read()
+	c := br.Clone()
+	for i := 0; i < 5; i++ {
+		br.Read()
+	}
+	if n := len(br.buf.rows); n != 5 {
+		t.Fatalf("%d rows kept for the clone, want 5", n)
+	}
+	if row, _ := c.Read(); row[0] != "b" {
+		t.Fatalf("clone read %q, want b", row)
+	}
+	c.Close()
+	if n := len(br.buf.rows); n != 0 {
+		t.Fatalf("%d rows kept after Close, want 0", n)
+	}
+	if _, err := c.Read(); err == nil {
+		t.Fatal("read from a closed clone")
+	}
+	br.Read()
+	if n := len(br.buf.rows); n != 0 {
+		t.Fatalf("%d rows kept without a clone, want 0", n)
+	}
+}
+
+// readRows reads rdr to the end.
+func readRows(t *testing.T, rdr Reader) [][]string {
+	t.Helper()
+	var rows [][]string
+	for {
+		row, err := rdr.Read()
+		if err == io.EOF {
+			return rows
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		rows = append(rows, row)
+	}
+}
+
+func TestSamplingReader(t *testing.T) {
+	rows := [][]string{{"N"}}
+	for i := 0; i < 20; i++ {
+		rows = append(rows, []string{strconv.Itoa(i)})
+	}
+	got := readRows(t, NewSamplingReader(NewSliceReader(rows), 5, 1))
+	if len(got) != 6 || got[0][0] != "N" {
+		t.Fatalf("got %q, want the header and 5 rows", got)
+	}
+	seen := make(map[string]bool)
+	for _, row := range got[1:] {
+		if n, err := strconv.Atoi(row[0]); err != nil || n < 0 || n >= 20 || seen[row[0]] {
+			t.Fatalf("bad sample %q", got)
+		}
+		seen[row[0]] = true
+	}
+	if again := readRows(t, NewSamplingReader(NewSliceReader(rows), 5, 1)); !reflect.DeepEqual(again, got) {
+		t.Fatalf("seed 1 gave %q, then %q", got, again)
+	}
+	if all := readRows(t, NewSamplingReader(NewSliceReader(rows[:4]), 5, 1)); !reflect.DeepEqual(all, rows[:4]) {
+		t.Fatalf("got %q, want all of %q", all, rows[:4])
+	}
+}
+
+func TestDedupReader(t *testing.T) {
+	rows := [][]string{{"ID", "V"}, {"1", "a"}, {"2", "b"}, {"1", "c"}, {"3", "d"}}
+	got := readRows(t, NewDedupReader(NewSliceReader(rows), []string{"id"}))
+	if want := [][]string{{"ID", "V"}, {"1", "a"}, {"2", "b"}, {"3", "d"}}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	rows = [][]string{{"V"}, {"a"}, {"b"}, {"a"}, {"b"}, {"b"}}
+	got = readRows(t, NewDedupReader(NewSliceReader(rows), nil, WithDedupLimit(1)))
+	if want := [][]string{{"V"}, {"a"}, {"b"}, {"a"}, {"b"}}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("with a limit got %q, want %q", got, want)
+	}
+	if _, err := NewDedupReader(NewSliceReader(rows), []string{"X"}).Read(); err == nil {
+		t.Fatal("unknown key column passed")
+	}
+}
+
+func TestSortReader(t *testing.T) {
+	rows := [][]string{{"K", "V"}, {"10", "a"}, {"9", "b"}, {"x", "c"}, {"10", "d"}}
+	got := readRows(t, NewSortReader(NewSliceReader(rows), "k", true))
+	if want := [][]string{{"K", "V"}, {"10", "a"}, {"10", "d"}, {"9", "b"}, {"x", "c"}}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("as strings got %q, want %q", got, want)
+	}
+	got = readRows(t, NewSortReader(NewSliceReader(rows), "K", true, WithNumericSort(true)))
+	if want := [][]string{{"K", "V"}, {"9", "b"}, {"10", "a"}, {"10", "d"}, {"x", "c"}}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("as numbers got %q, want %q", got, want)
+	}
+	got = readRows(t, NewSortReader(NewSliceReader(rows), "K", false, WithNumericSort(true)))
+	if want := [][]string{{"K", "V"}, {"10", "a"}, {"10", "d"}, {"9", "b"}, {"x", "c"}}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("descending got %q, want %q", got, want)
+	}
+}
+
+func TestGroupReader(t *testing.T) {
+	rows := [][]string{{"C", "N"}, {"a", "1"}, {"a", "2"}, {"b", "3"}, {"a", "4"}}
+	gr := NewGroupReader(NewSliceReader(rows), "c")
+	var got [][][]string
+	for {
+		group, err := gr.ReadGroup()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, group)
+	}
+	want := [][][]string{{{"a", "1"}, {"a", "2"}}, {{"b", "3"}}, {{"a", "4"}}}
+	if !reflect.DeepEqual(got, want) || !reflect.DeepEqual(gr.Headers, rows[0]) {
+		t.Fatalf("got %q with headers %q, want %q", got, gr.Headers, want)
+	}
+	if _, err := NewGroupReader(NewSliceReader(rows), "X").ReadGroup(); err == nil {
+		t.Fatal("unknown key column passed")
+	}
+}
@@ -0,0 +1,50 @@
+package csvdata
+
+import (
+	"reflect"
+	"strings"
+)
+
+// StructScanner returns pointers to the fields of the struct that ps
+// points to, one for each of cols, matched by column name as NewReadIter
+// would match headers. It is meant for database/sql:
+//
+//	cols, _ := rows.Columns()
+//	for rows.Next() {
+//	   err = rows.Scan(csvdata.StructScanner(p, cols)...)
+//	}
+//
+// A column without a field gets a pointer to a value that is thrown away.
+// It panics if ps is not a pointer to a struct or its tags are invalid.
+func StructScanner(ps interface{}, cols []string) []interface{} {
+	v := reflect.ValueOf(ps).Elem()
+	sfs, err := typeFields(v.Type())
+	if err != nil {
+		panic(err)
+	}
+	dest := make([]interface{}, len(cols))
+	for k, col := range cols {
+		dest[k] = new(interface{})
+		for _, sf := range sfs {
+			if sf.kind != none_k && scanName(sf, col) {
+				dest[k] = fieldByIndex(v, sf.index).Addr().Interface()
+				break
+			}
+		}
+	}
+	return dest
+}
+
+// scanName reports whether col is the name or an alias of sf, ignoring
+// case.
+func scanName(sf structField, col string) bool {
+	if strings.EqualFold(sf.name, col) {
+		return true
+	}
+	for _, alias := range sf.aliases {
+		if strings.EqualFold(alias, col) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,29 @@
+package csvdata
+
+import "testing"
+
+func TestStructScanner(t *testing.T) {
+	var p struct {
+		ID   int
+		Name string `alias:"full_name"`
+	}
+	dest := StructScanner(&p, []string{"id", "full_name", "other"})
+	*dest[0].(*int) = 7
+	*dest[1].(*string) = "x"
+	*dest[2].(*interface{}) = 1
+	if p.ID != 7 || p.Name != "x" {
+		t.Fatalf("got %+v", p)
+	}
+}
+
+func TestStructScannerBadTag(t *testing.T) {
+	var p struct {
+		N int `min:"x"`
+	}
+	defer func() {
+		if recover() == nil {
+			t.Fatal("no panic for a bad tag")
+		}
+	}()
+	StructScanner(&p, []string{"N"})
+}
@@ -0,0 +1,228 @@
+package csvdata
+
+import (
+	"fmt"
+	"go/format"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// DefaultInferRows is the number of data rows InferSchema looks at.
+const DefaultInferRows = 100
+
+// InferSchema reads the header row and up to DefaultInferRows rows of rdr
+// and returns the source of a Go struct named typeName that can read
+// them. Each column becomes an int, float64, bool or string field, the
+// narrowest type that every non-empty cell converts to; an int, float64 or
+// bool column with an empty cell becomes a pointer field, nil when empty.
+func InferSchema(rdr Reader, typeName string) (string, error) {
+	return InferSchemaRows(rdr, typeName, DefaultInferRows)
+}
+
+// InferSchemaRows is InferSchema looking at up to n data rows.
+func InferSchemaRows(rdr Reader, typeName string, n int) (string, error) {
+	headers, err := rdr.Read()
+	if err != nil {
+		return "", err
+	}
+	removeBOM(headers)
+	kinds := make([]int, len(headers)) // none_k until a cell is seen
+	empty := make([]bool, len(headers))
+	for i := 0; i < n; i++ {
+		row, err := rdr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+		for k := range kinds {
+			if k < len(row) && row[k] != "" {
+				kinds[k] = widenKind(kinds[k], row[k])
+			} else {
+				empty[k] = true
+			}
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "type %s struct {\n", typeName)
+	used := make(map[string]bool)
+	for k, h := range headers {
+		name := goName(h, k)
+		for i := 2; used[name]; i++ {
+			name = goName(h, k) + strconv.Itoa(i)
+		}
+		used[name] = true
+		tag := "field:" + strconv.Quote(h)
+		if strings.Contains(tag, "`") {
+			tag = strconv.Quote(tag)
+		} else {
+			tag = "`" + tag + "`"
+		}
+		typ := kindTypeName(kinds[k])
+		if empty[k] && typ != "string" {
+			typ = "*" + typ
+		}
+		fmt.Fprintf(&b, "\t%s %s %s\n", name, typ, tag)
+	}
+	b.WriteString("}\n")
+	src, err := format.Source([]byte(b.String()))
+	if err != nil {
+		return "", err
+	}
+	return string(src), nil
+}
+
+// widenKind returns the narrowest of int_k, float_k, bool_k and string_k
+// that both kind and the cell fit.
+func widenKind(kind int, cell string) int {
+	_, ierr := strconv.ParseInt(cell, 10, 64)
+	_, ferr := strconv.ParseFloat(cell, 64)
+	_, berr := strconv.ParseBool(cell)
+	switch {
+	case (kind == none_k || kind == int_k) && ierr == nil:
+		return int_k
+	case (kind == none_k || kind == int_k || kind == float_k) && ferr == nil:
+		return float_k
+	case (kind == none_k || kind == bool_k) && berr == nil:
+		return bool_k
+	}
+	return string_k
+}
+
+// kindTypeName returns the Go type written for an inferred kind.
+func kindTypeName(kind int) string {
+	switch kind {
+	case int_k:
+		return "int"
+	case float_k:
+		return "float64"
+	case bool_k:
+		return "bool"
+	}
+	return "string"
+}
+
+// goName turns the header of column k into an exported Go identifier,
+// e.g. "first name" into FirstName.
+func goName(header string, k int) string {
+	var b strings.Builder
+	upper := true
+	for _, c := range header {
+		if !unicode.IsLetter(c) && !unicode.IsDigit(c) {
+			upper = true
+			continue
+		}
+		if upper {
+			c = unicode.ToUpper(c)
+			upper = false
+		}
+		b.WriteRune(c)
+	}
+	name := b.String()
+	if name == "" {
+		return "Col" + strconv.Itoa(k)
+	}
+	if first := []rune(name)[0]; !unicode.IsUpper(first) {
+		// a digit, or a letter without case
+		name = "X" + name
+	}
+	return name
+}
+
+// SchemaDiff lists the differences between two header rows.
+type SchemaDiff struct {
+	Added   []string // in actual only
+	Removed []string // in expected only
+	Renamed []Rename
+}
+
+// A Rename is a column of the expected headers that seems to have been
+// renamed in the actual ones.
+type Rename struct {
+	From, To string
+}
+
+// DiffSchema compares the expected headers with the actual ones. A
+// removed column is taken as renamed to an added one at the same
+// position, or else to the closest added one, if their names are within a
+// Levenshtein distance of half the longer name.
+func DiffSchema(expected []string, actual []string) (diff SchemaDiff) {
+	added := make(map[int]bool) // index in actual
+	for k, h := range actual {
+		if indexOf(expected, h) < 0 {
+			added[k] = true
+		}
+	}
+	for k, h := range expected {
+		if indexOf(actual, h) >= 0 {
+			continue
+		}
+		best, bestDist := -1, 0
+		if added[k] && closeNames(h, actual[k]) {
+			best = k
+		} else {
+			for j := range actual {
+				if d := levenshtein(h, actual[j]); added[j] && closeNames(h, actual[j]) && (best < 0 || d < bestDist) {
+					best, bestDist = j, d
+				}
+			}
+		}
+		if best < 0 {
+			diff.Removed = append(diff.Removed, h)
+			continue
+		}
+		delete(added, best)
+		diff.Renamed = append(diff.Renamed, Rename{From: h, To: actual[best]})
+	}
+	for k, h := range actual {
+		if added[k] {
+			diff.Added = append(diff.Added, h)
+		}
+	}
+	return
+}
+
+// indexOf returns the index of s in list, or -1.
+func indexOf(list []string, s string) int {
+	for k, v := range list {
+		if v == s {
+			return k
+		}
+	}
+	return -1
+}
+
+// closeNames reports whether a and b are close enough to be a rename.
+func closeNames(a, b string) bool {
+	n := len([]rune(a))
+	if m := len([]rune(b)); m > n {
+		n = m
+	}
+	return levenshtein(a, b)*2 <= n
+}
+
+// levenshtein returns the edit distance between a and b, in runes.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,60 @@
+package csvdata
+
+import (
+	"encoding/csv"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestInferSchemaEmptyCells(t *testing.T) {
+	const data = "F,B,I,S\n1.5,true,1,x\n,,,\n"
+	src, err := InferSchema(csv.NewReader(strings.NewReader(data)), "Row")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"F *float64", "B *bool", "I *int", "S string"} {
+		if !strings.Contains(strings.Join(strings.Fields(src), " "), want) {
+			t.Errorf("no %q in\n%s", want, src)
+		}
+	}
+	// the struct it describes
+	var p struct {
+		F *float64 `field:"F"`
+		B *bool    `field:"B"`
+		I *int     `field:"I"`
+		S string   `field:"S"`
+	}
+	rs, err := NewReadIter(csv.NewReader(strings.NewReader(data)), &p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for rs.Get() {
+	}
+	if rs.Error != nil {
+		t.Fatal(rs.Error)
+	}
+	if p.F != nil || p.B != nil || p.I != nil {
+		t.Fatal("empty cells did not give nil pointers")
+	}
+}
+
+func TestDiffSchema(t *testing.T) {
+	diff := DiffSchema(
+		[]string{"id", "name", "email", "zip"},
+		[]string{"id", "fullname", "e-mail", "phone"},
+	)
+	want := SchemaDiff{
+		Added:   []string{"phone"},
+		Removed: []string{"zip"},
+		Renamed: []Rename{{From: "name", To: "fullname"}, {From: "email", To: "e-mail"}},
+	}
+	if !reflect.DeepEqual(diff, want) {
+		t.Fatalf("got %+v, want %+v", diff, want)
+	}
+	// a rename away from its position is found by distance
+	diff = DiffSchema([]string{"a", "customer"}, []string{"customers", "a"})
+	if len(diff.Renamed) != 1 || diff.Renamed[0] != (Rename{"customer", "customers"}) {
+		t.Fatalf("got %+v", diff)
+	}
+}
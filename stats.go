@@ -0,0 +1,94 @@
+package csvdata
+
+import (
+	"strconv"
+	"unicode/utf8"
+)
+
+// ColumnStat holds the statistics of one column gathered by a
+// StatsCollector. Min and Max cover the cells that parse as numbers;
+// MinLen and MaxLen cover the non-empty cells, in characters.
+type ColumnStat struct {
+	Count    int // rows seen
+	Distinct int // different values, the empty cell included
+	Empty    int
+	Numeric  int // cells that parse as numbers
+	Min, Max float64
+	MinLen   int
+	MaxLen   int
+}
+
+// StatsCollector wraps a ReadIter and gathers statistics on the cells of
+// every header column as rows are read with its Get method.
+type StatsCollector struct {
+	*ReadIter
+	stats  []ColumnStat
+	values []map[string]struct{}
+}
+
+// Creates a new StatsCollector reading from rs.
+func NewStatsCollector(rs *ReadIter) *StatsCollector {
+	this := &StatsCollector{ReadIter: rs}
+	this.stats = make([]ColumnStat, len(rs.Headers))
+	this.values = make([]map[string]struct{}, len(rs.Headers))
+	for k := range this.values {
+		this.values[k] = make(map[string]struct{})
+	}
+	return this
+}
+
+// The Get method reads the next row like ReadIter.Get and adds its cells
+// to the statistics.
+func (this *StatsCollector) Get() bool {
+	if !this.ReadIter.Get() {
+		return false
+	}
+	for k := range this.stats {
+		cell := ""
+		if k < len(this.RawRow) {
+			cell = this.RawRow[k]
+		}
+		this.add(k, cell)
+	}
+	return true
+}
+
+// add counts one cell of column k.
+func (this *StatsCollector) add(k int, cell string) {
+	st := &this.stats[k]
+	st.Count = st.Count + 1
+	if _, seen := this.values[k][cell]; !seen {
+		this.values[k][cell] = struct{}{}
+		st.Distinct = st.Distinct + 1
+	}
+	if cell == "" {
+		st.Empty = st.Empty + 1
+		return
+	}
+	if n := utf8.RuneCountInString(cell); st.Count == st.Empty+1 {
+		st.MinLen, st.MaxLen = n, n
+	} else if n < st.MinLen {
+		st.MinLen = n
+	} else if n > st.MaxLen {
+		st.MaxLen = n
+	}
+	if f, err := strconv.ParseFloat(cell, 64); err == nil {
+		if st.Numeric == 0 || f < st.Min {
+			st.Min = f
+		}
+		if st.Numeric == 0 || f > st.Max {
+			st.Max = f
+		}
+		st.Numeric = st.Numeric + 1
+	}
+}
+
+// ColumnStats returns the statistics of the column with the given header,
+// or a zero ColumnStat if there is no such column.
+func (this *StatsCollector) ColumnStats(header string) ColumnStat {
+	k := this.HeaderIndex(header)
+	if k < 0 || k >= len(this.stats) {
+		return ColumnStat{}
+	}
+	return this.stats[k]
+}
@@ -0,0 +1,37 @@
+package csvdata
+
+import "context"
+
+// Stream reads the remaining rows in a new goroutine and sends a copy of
+// the struct for each row to the returned channel, which has a buffer of
+// bufSize and is closed at the end. The error channel receives at most one
+// error: the read error, or the context's error if ctx is cancelled first.
+// The ReadIter must not be used by the caller while the stream is running.
+func (this *ReadIter) Stream(ctx context.Context, bufSize int) (<-chan interface{}, <-chan error) {
+	out := make(chan interface{}, bufSize)
+	errc := make(chan error, 1)
+	go func() {
+		defer close(errc)
+		defer close(out)
+		for {
+			// a ready send may win the select below over ctx.Done
+			if err := ctx.Err(); err != nil {
+				errc <- err
+				return
+			}
+			if !this.Get() {
+				break
+			}
+			select {
+			case out <- copyStruct(this.value).Interface():
+			case <-ctx.Done():
+				errc <- ctx.Err()
+				return
+			}
+		}
+		if this.Error != nil {
+			errc <- this.Error
+		}
+	}()
+	return out, errc
+}
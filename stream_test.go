@@ -0,0 +1,41 @@
+package csvdata
+
+import (
+	"context"
+	"testing"
+)
+
+func TestStreamEmbeddedPointer(t *testing.T) {
+	rs, err := NewReadIter(testEmbeddedReader(), new(testEmbedded))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, errc := rs.Stream(context.Background(), 2)
+	var all []*testEmbedded
+	for v := range out {
+		p := v.(testEmbedded)
+		all = append(all, &p)
+	}
+	if err := <-errc; err != nil {
+		t.Fatal(err)
+	}
+	checkEmbedded(t, all)
+}
+
+func TestStreamCancelled(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		rs, err := NewReadIter(testEmbeddedReader(), new(testEmbedded))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		out, errc := rs.Stream(ctx, 2)
+		for range out {
+			t.Fatal("a row was sent after cancel")
+		}
+		if err := <-errc; err != context.Canceled {
+			t.Fatalf("got %v, want %v", err, context.Canceled)
+		}
+	}
+}
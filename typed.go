@@ -0,0 +1,45 @@
+package csvdata
+
+import "reflect"
+
+// ReadAll reads every remaining row of rdr into values made by factory.
+// On an error it returns the rows collected so far along with the error.
+func ReadAll[T any](rdr Reader, factory func() *T, opts ...ReadIterOption) ([]*T, error) {
+	p := factory()
+	rs, err := NewReadIter(rdr, p, opts...)
+	if err != nil {
+		return nil, err
+	}
+	var all []*T
+	for rs.Get() {
+		all = append(all, copyStruct(reflect.ValueOf(p).Elem()).Addr().Interface().(*T))
+	}
+	return all, rs.Error
+}
+
+// ReadIterTyped is a ReadIter for a struct type known at compile time.
+// It owns the struct being filled, so Get can hand out a fresh value for
+// every row. Error, Line and the other ReadIter fields are promoted.
+type ReadIterTyped[T any] struct {
+	*ReadIter
+	p *T
+}
+
+// Creates a new typed iterator from a Reader source.
+func NewReadIterTyped[T any](rdr Reader, opts ...ReadIterOption) (*ReadIterTyped[T], error) {
+	p := new(T)
+	rs, err := NewReadIter(rdr, p, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &ReadIterTyped[T]{ReadIter: rs, p: p}, nil
+}
+
+// The Get method reads the next row into a newly allocated T. As with
+// ReadIter.Get, false means EOF or an error in Error.
+func (this *ReadIterTyped[T]) Get() (*T, bool) {
+	if !this.ReadIter.Get() {
+		return nil, false
+	}
+	return copyStruct(reflect.ValueOf(this.p).Elem()).Addr().Interface().(*T), true
+}
@@ -0,0 +1,54 @@
+package csvdata
+
+import (
+	"encoding/csv"
+	"strings"
+	"testing"
+)
+
+type EmbeddedMeta struct {
+	Tag string
+}
+
+type testEmbedded struct {
+	*EmbeddedMeta // exported, so that it can be allocated
+	Name          string
+}
+
+func testEmbeddedReader() Reader {
+	return csv.NewReader(strings.NewReader("Tag,Name\na,x\nb,y\n"))
+}
+
+func checkEmbedded(t *testing.T, got []*testEmbedded) {
+	t.Helper()
+	var s []string
+	for _, p := range got {
+		s = append(s, p.Tag+" "+p.Name)
+	}
+	if strings.Join(s, ",") != "a x,b y" {
+		t.Fatalf("got %q, want \"a x,b y\"", s)
+	}
+}
+
+func TestReadIterTypedEmbeddedPointer(t *testing.T) {
+	rs, err := NewReadIterTyped[testEmbedded](testEmbeddedReader())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var all []*testEmbedded
+	for p, ok := rs.Get(); ok; p, ok = rs.Get() {
+		all = append(all, p)
+	}
+	if rs.Error != nil {
+		t.Fatal(rs.Error)
+	}
+	checkEmbedded(t, all)
+}
+
+func TestReadAllEmbeddedPointer(t *testing.T) {
+	all, err := ReadAll(testEmbeddedReader(), func() *testEmbedded { return new(testEmbedded) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkEmbedded(t, all)
+}
@@ -0,0 +1,318 @@
+package csvdata
+
+import (
+	"encoding"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WriteIter is the counterpart of ReadIter: it writes user structs as
+// rows of a CSV file, using the same tags to name the columns. A field with
+// the omitempty option, as in `field:"Score,omitempty"`, is written as an
+// empty cell when it holds its zero value, instead of "0" or "false". A
+// slice field without a sep tag, which reads repeated columns, is not
+// written.
+type WriteIter struct {
+	Writer  *csv.Writer
+	Headers []string
+	typ     reflect.Type
+	fields  [][]int // index path of each column's field
+	kinds   []int
+	specs   []fieldSpec
+
+	// options
+	noHeader  bool
+	floatFmt  byte
+	floatPrec int
+	mapping   *FieldMapping
+}
+
+// A WriteIterOption configures a WriteIter; options are passed to
+// NewWriteIter and applied before the header row is written.
+type WriteIterOption func(*WriteIter)
+
+// mapType collects the columns to be written for the struct type st,
+// using the same fields that NewReadIter would fill.
+func (this *WriteIter) mapType(st reflect.Type) error {
+	sfs, err := typeFields(st)
+	if err != nil {
+		return err
+	}
+	for _, sf := range sfs {
+		if sf.kind == none_k {
+			return errors.New("cannot convert this type " + sf.typ.String())
+		}
+		// a slice without a sep tag gathers repeated columns, as many as
+		// the file has, so it has no fixed columns to be written to
+		if sf.kind == sep_k && sf.spec.sep == "" {
+			continue
+		}
+		name := sf.name
+		if col, ok := this.mapping.column(sf.spec.field); ok {
+			name = col
+		}
+		this.Headers = append(this.Headers, name)
+		this.fields = append(this.fields, sf.index)
+		this.kinds = append(this.kinds, sf.kind)
+		spec := sf.spec
+		if this.floatFmt != 0 && !spec.hasFormat {
+			spec.floatFmt, spec.floatPrec = this.floatFmt, this.floatPrec
+		}
+		this.specs = append(this.specs, spec)
+	}
+	return nil
+}
+
+// Creates a new iterator writing to w for a user-defined struct; the
+// header row is written immediately unless WithNoHeaderRow is given.
+func NewWriteIter(w io.Writer, ps interface{}, opts ...WriteIterOption) (this *WriteIter, err error) {
+	st := reflect.TypeOf(ps)
+	if st != nil && st.Kind() == reflect.Ptr {
+		st = st.Elem()
+	}
+	if st == nil || st.Kind() != reflect.Struct {
+		err = fmt.Errorf("cannot write this type %v, need a struct", st)
+		return
+	}
+	this = &WriteIter{Writer: csv.NewWriter(w), typ: st}
+	for _, opt := range opts {
+		opt(this)
+	}
+	if err = this.mapType(st); err != nil {
+		this = nil
+		return
+	}
+	if this.noHeader {
+		return
+	}
+	if err = this.WriteHeader(); err != nil {
+		this = nil
+	}
+	return
+}
+
+// WithNoHeaderRow stops NewWriteIter from writing the header row, so that
+// it can be written later with WriteHeader, or not at all.
+func WithNoHeaderRow() WriteIterOption {
+	return func(this *WriteIter) {
+		this.noHeader = true
+	}
+}
+
+// WithFloatFormat sets the fmt and prec arguments to strconv.FormatFloat
+// for all float fields, e.g. WithFloatFormat('f', 2) for amounts. The
+// default is 'g' and -1; a `format:"e:3"` tag on a field takes
+// precedence.
+func WithFloatFormat(fmt byte, prec int) WriteIterOption {
+	return func(this *WriteIter) {
+		this.floatFmt = fmt
+		this.floatPrec = prec
+	}
+}
+
+// WriteHeader writes the header row: the column names that NewReadIter
+// would match against the fields of the struct.
+func (this *WriteIter) WriteHeader() error {
+	return this.Writer.Write(this.Headers)
+}
+
+// The Put method writes one struct, passed by value or as a pointer, as
+// a row. Rows are buffered; call Flush when done.
+func (this *WriteIter) Put(ps interface{}) error {
+	row, err := this.row(ps)
+	if err != nil {
+		return err
+	}
+	return this.Writer.Write(row)
+}
+
+// row returns the cells of the struct ps in column order.
+func (this *WriteIter) row(ps interface{}) ([]string, error) {
+	v := reflect.ValueOf(ps)
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil, errors.New("cannot write a nil " + v.Type().String())
+		}
+		v = v.Elem()
+	}
+	if v.Type() != this.typ {
+		return nil, errors.New("cannot write " + v.Type().String() + " with a WriteIter for " + this.typ.String())
+	}
+	// Value methods are on the pointer, so we need an addressable copy
+	if !v.CanAddr() {
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v)
+		v = c
+	}
+	row := make([]string, len(this.fields))
+	for i, index := range this.fields {
+		f, ok := fieldByIndexRead(v, index)
+		if !ok {
+			// inside a nil embedded struct
+			continue
+		}
+		if this.specs[i].omitEmpty && isEmptyValue(f) {
+			continue
+		}
+		if this.specs[i].ptr {
+			// a nil pointer is written as an empty cell
+			if f.IsNil() {
+				continue
+			}
+			f = f.Elem()
+		}
+		cell, err := formatValue(f, this.kinds[i], &this.specs[i])
+		if err != nil {
+			return nil, err
+		}
+		row[i] = cell
+	}
+	return row, nil
+}
+
+// isEmptyValue reports whether v is empty in the sense of omitempty in
+// encoding/json.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map, reflect.String:
+		return v.Len() == 0
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	case reflect.Struct:
+		return false
+	}
+	return v.IsZero()
+}
+
+// fieldByIndexRead is like reflect.Value.FieldByIndex but reports false
+// rather than panic at a nil embedded struct pointer.
+func fieldByIndexRead(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return v, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+// formatValue is the inverse of setElem: it returns the cell for f, which
+// must be addressable. A TextMarshaler is used in preference to anything
+// else, unless a time.Time has an explicit format.
+func formatValue(f reflect.Value, kind int, spec *fieldSpec) (string, error) {
+	if kind == time_k {
+		t := f.Convert(timeType).Interface().(time.Time)
+		// the zero time is written as an empty cell, as it is read
+		if t.IsZero() {
+			return "", nil
+		}
+		switch {
+		case spec.format == "epoch":
+			return strconv.FormatInt(t.Unix(), 10), nil
+		case spec.format == "epoch_ms":
+			return strconv.FormatInt(t.UnixMilli(), 10), nil
+		case spec.hasFormat:
+			return t.Format(spec.format), nil
+		}
+	}
+	if m, ok := f.Addr().Interface().(encoding.TextMarshaler); ok {
+		b, err := m.MarshalText()
+		return string(b), err
+	}
+	switch kind {
+	case string_k:
+		return f.String(), nil
+	case int_k:
+		return strconv.FormatInt(f.Int(), spec.intBase()), nil
+	case uint_k:
+		return strconv.FormatUint(f.Uint(), spec.intBase()), nil
+	case float_k:
+		return strconv.FormatFloat(f.Float(), spec.floatFmt, spec.floatPrec, f.Type().Bits()), nil
+	case bool_k:
+		return strconv.FormatBool(f.Bool()), nil
+	case time_k:
+		return f.Convert(timeType).Interface().(time.Time).Format(spec.format), nil
+	case duration_k:
+		return time.Duration(f.Int()).String(), nil
+	case value_k:
+		return f.Addr().Interface().(Value).String(), nil
+	case iface_k:
+		if f.IsNil() {
+			return "", nil
+		}
+		return f.Interface().(Value).String(), nil
+	case sub_k:
+		wi := &WriteIter{typ: f.Type()}
+		if err := wi.mapType(wi.typ); err != nil {
+			return "", err
+		}
+		cells, err := wi.row(f.Addr().Interface())
+		if err != nil {
+			return "", err
+		}
+		pairs := make([]string, len(cells))
+		for i, cell := range cells {
+			pairs[i] = wi.Headers[i] + string(spec.kvSep) + cell
+		}
+		return strings.Join(pairs, string(spec.rechunk)), nil
+	case text_k, verr_k:
+		if s, ok := f.Addr().Interface().(fmt.Stringer); ok {
+			return s.String(), nil
+		}
+	case sep_k:
+		parts := make([]string, f.Len())
+		for i := range parts {
+			part, err := formatValue(f.Index(i), spec.elemKind, spec)
+			if err != nil {
+				return "", err
+			}
+			parts[i] = part
+		}
+		return strings.Join(parts, spec.sep), nil
+	}
+	return "", nil
+}
+
+// WriteAll writes rows, a slice of structs or of pointers to structs, to
+// w as a CSV file with a header row.
+func WriteAll(w io.Writer, rows interface{}, opts ...WriteIterOption) error {
+	rv := reflect.ValueOf(rows)
+	if !rv.IsValid() {
+		return errors.New("cannot write nil, need a slice of structs")
+	}
+	if rv.Kind() != reflect.Slice {
+		return errors.New("cannot write " + rv.Type().String() + ", need a slice of structs")
+	}
+	st := rv.Type().Elem()
+	if st.Kind() == reflect.Ptr {
+		st = st.Elem()
+	}
+	if st.Kind() != reflect.Struct {
+		return errors.New("cannot write " + rv.Type().String() + ", need a slice of structs")
+	}
+	wi, err := NewWriteIter(w, reflect.New(st).Interface(), opts...)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < rv.Len(); i++ {
+		if err = wi.Put(rv.Index(i).Interface()); err != nil {
+			return err
+		}
+	}
+	return wi.Flush()
+}
+
+// Flush writes any buffered rows to the underlying io.Writer.
+func (this *WriteIter) Flush() error {
+	this.Writer.Flush()
+	return this.Writer.Error()
+}
@@ -0,0 +1,38 @@
+package csvdata
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestNewWriteIterNotStruct(t *testing.T) {
+	var b bytes.Buffer
+	for _, ps := range []interface{}{nil, 3, new(int)} {
+		if _, err := NewWriteIter(&b, ps); err == nil {
+			t.Errorf("NewWriteIter(%v) did not fail", ps)
+		}
+	}
+}
+
+func TestWriteAllBadRows(t *testing.T) {
+	var b bytes.Buffer
+	for _, rows := range []interface{}{nil, 3, []int{1}, []*int{nil}} {
+		if err := WriteAll(&b, rows); err == nil {
+			t.Errorf("WriteAll(%v) did not fail", rows)
+		}
+	}
+}
+
+func TestWriteSkipsRepeatedColumnSlice(t *testing.T) {
+	type row struct {
+		ID   int
+		Tags []string `field:"Tag"`
+	}
+	var b bytes.Buffer
+	if err := WriteAll(&b, []row{{1, []string{"a", "b"}}}); err != nil {
+		t.Fatal(err)
+	}
+	if b.String() != "ID\n1\n" {
+		t.Fatalf("got %q", b.String())
+	}
+}